@@ -0,0 +1,115 @@
+// Copyright 2026 The golang.design Initiative Authors.
+// All rights reserved. Use of this source code is governed
+// by a MIT license that can be found in the LICENSE file.
+
+package hotkey
+
+import "errors"
+
+// Combination is a key together with its modifiers.
+type Combination struct {
+	Mods []Modifier
+	Key  Key
+}
+
+// String returns a string representation of the combination.
+func (c Combination) String() string { return combination(c.Mods, c.Key) }
+
+// Available reports whether the combination of mods and key can currently
+// be registered. A combination registered by another Hotkey of this
+// process is available, since Register shares it. Otherwise, Available
+// grabs the combination and releases it right away, detecting conflicts
+// the same way Register does, so nothing stays registered afterwards. A
+// combination taken by another application is reported as false with a
+// nil error; any other failure, such as ErrKeyNotOnKeyboard, is returned
+// as an error.
+//
+// On macOS, where event taps do not conflict with each other, only the key
+// and the Accessibility permission are checked. The answer may be outdated
+// as soon as it is returned, so Register can still fail for a combination
+// reported available.
+func Available(mods []Modifier, key Key) (bool, error) {
+	if isShared(mods, key) {
+		return true, nil
+	}
+	err := (&Hotkey{mods: mods, key: key}).probe()
+	var cerr *ConflictError
+	if errors.As(err, &cerr) && cerr.Temporary() {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Alternatives returns up to n available combinations near mods+key,
+// nearest first, e.g. to suggest replacements for a combination that is
+// already taken. The candidates are the same key with one more modifier,
+// followed by the neighbours of key on its keyboard row (or among the
+// function keys) with the same modifiers, closest first. Candidates whose
+// key is not on the keyboard are skipped.
+func Alternatives(mods []Modifier, key Key, n int) ([]Combination, error) {
+	var found []Combination
+	for _, c := range candidates(mods, key, alternativeModifiers, keyRows) {
+		if len(found) >= n {
+			break
+		}
+		ok, err := Available(c.Mods, c.Key)
+		if errors.Is(err, ErrKeyNotOnKeyboard) {
+			continue
+		}
+		if err != nil {
+			return found, err
+		}
+		if ok {
+			found = append(found, c)
+		}
+	}
+	return found, nil
+}
+
+// candidates lists the combinations near mods+key, nearest first: key with
+// one more modifier out of extra, then the neighbours of key within its
+// row, alternating left and right with growing distance.
+func candidates(mods []Modifier, key Key, extra []Modifier, rows [][]Key) []Combination {
+	var cs []Combination
+	for _, m := range extra {
+		if hasModifier(mods, m) {
+			continue
+		}
+		ms := append(mods[:len(mods):len(mods)], m)
+		cs = append(cs, Combination{Mods: ms, Key: key})
+	}
+
+	for _, row := range rows {
+		at := -1
+		for i, k := range row {
+			if k == key {
+				at = i
+				break
+			}
+		}
+		if at < 0 {
+			continue
+		}
+		for d := 1; d < len(row); d++ {
+			for _, i := range []int{at - d, at + d} {
+				if i >= 0 && i < len(row) {
+					cs = append(cs, Combination{Mods: mods, Key: row[i]})
+				}
+			}
+		}
+		break
+	}
+	return cs
+}
+
+func hasModifier(mods []Modifier, m Modifier) bool {
+	for _, x := range mods {
+		if x == m {
+			return true
+		}
+	}
+	return false
+}
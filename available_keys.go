@@ -0,0 +1,19 @@
+// Copyright 2026 The golang.design Initiative Authors.
+// All rights reserved. Use of this source code is governed
+// by a MIT license that can be found in the LICENSE file.
+
+//go:build windows || cgo
+
+package hotkey
+
+// keyRows are the rows of neighbouring keys among which Alternatives looks
+// for candidates, the same on every platform; the modifiers it adds are
+// in alternativeModifiers. Builds without a backend have neither.
+var keyRows = [][]Key{
+	{KeyF1, KeyF2, KeyF3, KeyF4, KeyF5, KeyF6, KeyF7, KeyF8, KeyF9, KeyF10,
+		KeyF11, KeyF12, KeyF13, KeyF14, KeyF15, KeyF16, KeyF17, KeyF18, KeyF19, KeyF20},
+	{Key1, Key2, Key3, Key4, Key5, Key6, Key7, Key8, Key9, Key0},
+	{KeyQ, KeyW, KeyE, KeyR, KeyT, KeyY, KeyU, KeyI, KeyO, KeyP},
+	{KeyA, KeyS, KeyD, KeyF, KeyG, KeyH, KeyJ, KeyK, KeyL},
+	{KeyZ, KeyX, KeyC, KeyV, KeyB, KeyN, KeyM},
+}
@@ -0,0 +1,64 @@
+// Copyright 2026 The golang.design Initiative Authors.
+// All rights reserved. Use of this source code is governed
+// by a MIT license that can be found in the LICENSE file.
+
+package hotkey
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RegisterError is returned by RegisterAll and RegisterSome. It lists every
+// hotkey that failed to register together with its error, and can be
+// matched with errors.Is and errors.As against any of those errors.
+type RegisterError struct {
+	Failed []*Hotkey
+	Errs   []error // Errs[i] is the reason Failed[i] did not register
+}
+
+func (e *RegisterError) Error() string {
+	msgs := make([]string, len(e.Errs))
+	for i, err := range e.Errs {
+		msgs[i] = err.Error()
+	}
+	return fmt.Sprintf("hotkey: %d hotkeys failed to register: %s", len(e.Errs), strings.Join(msgs, "; "))
+}
+
+func (e *RegisterError) Unwrap() []error { return e.Errs }
+
+// RegisterAll registers all hotkeys or none of them. Every hotkey is tried,
+// so that the returned *RegisterError lists all conflicting combinations at
+// once; if any of them fails, the hotkeys registered by this call are
+// unregistered again before it returns.
+func RegisterAll(hks ...*Hotkey) error {
+	ok, err := RegisterSome(hks...)
+	if err != nil {
+		for i := len(ok) - 1; i >= 0; i-- {
+			ok[i].Unregister()
+		}
+	}
+	return err
+}
+
+// RegisterSome registers as many of the hotkeys as possible. It returns the
+// hotkeys that were registered by this call, in order, and a
+// *RegisterError listing the others, or nil if all of them succeeded.
+func RegisterSome(hks ...*Hotkey) ([]*Hotkey, error) {
+	var (
+		ok   []*Hotkey
+		rerr RegisterError
+	)
+	for _, hk := range hks {
+		if err := hk.Register(); err != nil {
+			rerr.Failed = append(rerr.Failed, hk)
+			rerr.Errs = append(rerr.Errs, err)
+			continue
+		}
+		ok = append(ok, hk)
+	}
+	if len(rerr.Errs) > 0 {
+		return ok, &rerr
+	}
+	return ok, nil
+}
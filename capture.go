@@ -0,0 +1,69 @@
+// Copyright 2026 The golang.design Initiative Authors.
+// All rights reserved. Use of this source code is governed
+// by a MIT license that can be found in the LICENSE file.
+
+package hotkey
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// KeyEvent is a key event while the whole keyboard is captured.
+type KeyEvent struct {
+	Kind EventKind  // EventKeydown or EventKeyup
+	Key  Key        // the key, without the modifiers applied, e.g. KeyA for Shift+a
+	Mods []Modifier // the modifiers held, without CapsLock and NumLock
+	Text string     // the text typed by the key, if any, e.g. "A" for Shift+a
+}
+
+// keyboard is a capture of the whole keyboard, see captureKeyboard.
+type keyboard interface {
+	// next waits for the next key event.
+	next(ctx context.Context) (KeyEvent, error)
+	// close releases the keyboard.
+	close()
+}
+
+// captureWait bounds how long Capture waits for another client, such as
+// the passive grab of a hotkey that is still held, to release the
+// keyboard.
+const captureWait = time.Second
+
+// Capture grabs the whole keyboard and returns a channel that receives
+// every key event, including modifiers, until ctx is done. Then the
+// keyboard is released and the channel is closed. While the keyboard is
+// captured, no other application receives key events, and hotkeys do not
+// fire; this suits modes such as resizing a window or typing a search
+// query after a hotkey.
+//
+// On X11, the keyboard is grabbed on a connection of its own, which the X
+// server closes if the process dies, so a crash cannot leave the keyboard
+// grabbed. Capture is only supported on X11; elsewhere it returns
+// ErrUnsupported.
+func Capture(ctx context.Context) (<-chan KeyEvent, error) {
+	if !captureSupported {
+		return nil, ErrUnsupported
+	}
+	wait, cancel := context.WithTimeout(ctx, captureWait)
+	defer cancel()
+	kb, err := captureKeyboard(wait)
+	if err != nil {
+		return nil, fmt.Errorf("hotkey: failed to capture the keyboard: %w", err)
+	}
+
+	in, out := newEventChan[KeyEvent]()
+	go func() {
+		defer close(in)
+		defer kb.close()
+		for {
+			k, err := kb.next(ctx)
+			if err != nil {
+				return
+			}
+			in <- k
+		}
+	}()
+	return out, nil
+}
@@ -0,0 +1,22 @@
+// Copyright 2026 The golang.design Initiative Authors.
+// All rights reserved. Use of this source code is governed
+// by a MIT license that can be found in the LICENSE file.
+
+//go:build !(linux || openbsd) || !cgo
+
+package hotkey
+
+import "context"
+
+const (
+	captureSupported          = false
+	modifierOnlySupported     = false
+	anyKeySupported           = false
+	keyEscape             Key = 0
+)
+
+func captureKeyboard(ctx context.Context) (keyboard, error) {
+	return nil, ErrUnsupported
+}
+
+func isModifierKey(k Key) bool { return false }
@@ -0,0 +1,87 @@
+// Copyright 2026 The golang.design Initiative Authors.
+// All rights reserved. Use of this source code is governed
+// by a MIT license that can be found in the LICENSE file.
+
+package hotkey
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// registered holds every registered hotkey of the process for
+// UnregisterAll.
+var registered struct {
+	sync.Mutex
+	hks map[*Hotkey]struct{}
+}
+
+func track(hk *Hotkey) {
+	registered.Lock()
+	defer registered.Unlock()
+	if registered.hks == nil {
+		registered.hks = make(map[*Hotkey]struct{})
+	}
+	registered.hks[hk] = struct{}{}
+}
+
+func untrack(hk *Hotkey) {
+	registered.Lock()
+	defer registered.Unlock()
+	delete(registered.hks, hk)
+}
+
+// UnregisterAll unregisters every registered hotkey of the process. On
+// Linux (X11) in particular, grabs are otherwise held until the process
+// exits. Hotkeys unregistered concurrently are skipped, so the returned
+// error only joins unexpected failures.
+func UnregisterAll() error {
+	registered.Lock()
+	hks := make([]*Hotkey, 0, len(registered.hks))
+	for hk := range registered.hks {
+		hks = append(hks, hk)
+	}
+	registered.Unlock()
+
+	var errs []error
+	for _, hk := range hks {
+		if err := hk.Unregister(); err != nil && !errors.Is(err, ErrNotRegistered) {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// SignalContext returns a copy of parent that is done when one of sigs
+// arrives, after every hotkey of the process has been unregistered, or when
+// the returned stop function is called, whichever happens first. Without
+// sigs, os.Interrupt and SIGTERM are used. Like signal.NotifyContext, the
+// signals are no longer handled by their default action until stop is
+// called, so a daemon typically exits once the context is done:
+//
+//	ctx, stop := hotkey.SignalContext(context.Background())
+//	defer stop()
+//	...
+//	<-ctx.Done()
+func SignalContext(parent context.Context, sigs ...os.Signal) (ctx context.Context, stop context.CancelFunc) {
+	if len(sigs) == 0 {
+		sigs = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+	ctx, cancel := context.WithCancel(parent)
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, sigs...)
+	go func() {
+		select {
+		case <-c:
+			UnregisterAll()
+		case <-ctx.Done():
+		}
+		signal.Stop(c)
+		cancel()
+	}()
+	return ctx, cancel
+}
@@ -0,0 +1,15 @@
+// Copyright 2026 The golang.design Initiative Authors.
+// All rights reserved. Use of this source code is governed
+// by a MIT license that can be found in the LICENSE file.
+
+//go:build windows || linux || openbsd || darwin
+
+package hotkey
+
+import "golang.design/x/hotkey/mainthread"
+
+// MainThread is a Dispatcher that runs handlers on the main thread with
+// mainthread.Call. As with every use of the mainthread package, the
+// program must be started with mainthread.Init unless a GUI toolkit such
+// as Fyne, Ebiten or Gio already owns the main thread.
+func MainThread(f func()) { mainthread.Call(f) }
@@ -0,0 +1,105 @@
+// Copyright 2026 The golang.design Initiative Authors.
+// All rights reserved. Use of this source code is governed
+// by a MIT license that can be found in the LICENSE file.
+
+//go:build ignore
+
+// gen_keysym generates keysym_table_x11.go from X11/keysymdef.h, which
+// lists the character of each keysym in a comment such as
+// /* U+0104 LATIN CAPITAL LETTER A WITH OGONEK */. Run it with
+// go generate on a machine with the X11 headers, e.g. from the
+// x11proto-dev package.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+)
+
+var (
+	in  = flag.String("in", "/usr/include/X11/keysymdef.h", "the keysymdef.h to read")
+	out = flag.String("out", "keysym_table_x11.go", "the Go file to write")
+)
+
+// define matches a keysym with its character. The keypad keysyms list no
+// character, see keypadRunes.
+var define = regexp.MustCompile(`^#define XK_(\w+)\s+0x([0-9a-fA-F]+)\s*/\*\s*\(?U\+([0-9A-F]+)`)
+
+type keysym struct {
+	name string
+	ks   uint64
+	r    uint64
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("gen_keysym: ")
+	flag.Parse()
+
+	f, err := os.Open(*in)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+
+	var keysyms []keysym
+	seen := map[uint64]bool{}
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		m := define.FindStringSubmatch(s.Text())
+		if m == nil {
+			continue
+		}
+		ks, _ := strconv.ParseUint(m[2], 16, 32)
+		r, _ := strconv.ParseUint(m[3], 16, 32)
+		switch {
+		case ks >= 0x1000000: // Unicode keysyms, see keysymRune
+		case (ks >= 0x20 && ks <= 0x7e) || (ks >= 0xa0 && ks <= 0xff): // Latin-1
+		case r < 0x20 || (r >= 0x7f && r < 0xa0): // control characters
+		case seen[ks]: // an alias, the first name is kept
+		default:
+			seen[ks] = true
+			keysyms = append(keysyms, keysym{m[1], ks, r})
+		}
+	}
+	if err := s.Err(); err != nil {
+		log.Fatal(err)
+	}
+	slices.SortStableFunc(keysyms, func(a, b keysym) int { return int(a.ks) - int(b.ks) })
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, `// Copyright 2026 The golang.design Initiative Authors.
+// All rights reserved. Use of this source code is governed
+// by a MIT license that can be found in the LICENSE file.
+
+// Code generated by gen_keysym.go from X11/keysymdef.h; DO NOT EDIT.
+
+//go:build linux || openbsd
+
+package hotkey
+
+// keysymRunes maps the legacy keysyms, which predate the Unicode keysyms,
+// to their characters as listed in X11/keysymdef.h.
+var keysymRunes = map[Key]rune{
+`)
+	for _, k := range keysyms {
+		fmt.Fprintf(&b, "\t0x%04x: 0x%04x, // %s\n", k.ks, k.r, k.name)
+	}
+	fmt.Fprintf(&b, "}\n")
+
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(*out, src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,175 @@
+// Copyright 2026 The golang.design Initiative Authors.
+// All rights reserved. Use of this source code is governed
+// by a MIT license that can be found in the LICENSE file.
+
+package hotkey
+
+import (
+	"fmt"
+	"time"
+)
+
+// Tap is a series of presses of a hotkey in quick succession, such as a
+// double-tap.
+type Tap struct {
+	Count int // the number of presses
+}
+
+// Taps returns a channel that receives a Tap for every series of presses
+// of the hotkey in which each press follows the previous one within
+// interval, together with a function that stops the detection and closes
+// the channel. A series ends once interval passes without another press,
+// so a single press is only reported after interval; if maxCount is
+// positive, a series also ends, right away, with its maxCount-th press.
+func (hk *Hotkey) Taps(interval time.Duration, maxCount int) (<-chan Tap, func()) {
+	events, unsubscribe := hk.Subscribe(Buffer{})
+	in, out := newEventChan[Tap]()
+	go func() {
+		defer close(in)
+		var (
+			count int
+			timer *time.Timer
+			ended <-chan time.Time // fires when the series ends
+		)
+		end := func() {
+			in <- Tap{Count: count}
+			count, ended = 0, nil
+			if timer != nil {
+				timer.Stop()
+			}
+		}
+		for {
+			select {
+			case e, ok := <-events:
+				if !ok {
+					if count > 0 {
+						end()
+					}
+					return
+				}
+				if e.Kind != EventKeydown {
+					continue
+				}
+				count++
+				if count == maxCount {
+					end()
+					continue
+				}
+				if timer != nil {
+					timer.Stop()
+				}
+				timer = time.NewTimer(interval)
+				ended = timer.C
+			case <-ended:
+				end()
+			}
+		}
+	}()
+	return out, unsubscribe
+}
+
+// HoldEventKind tells what happened to a held hotkey.
+type HoldEventKind uint8
+
+// The kinds of hold events.
+const (
+	ShortPress HoldEventKind = iota + 1 // the hotkey was released before the threshold
+	HoldStart                           // the hotkey is held for the threshold
+	HoldEnd                             // the held hotkey was released
+)
+
+// String returns the name of the hold event kind.
+func (k HoldEventKind) String() string {
+	switch k {
+	case ShortPress:
+		return "short press"
+	case HoldStart:
+		return "hold start"
+	case HoldEnd:
+		return "hold end"
+	}
+	return fmt.Sprintf("HoldEventKind(%d)", uint8(k))
+}
+
+// HoldEvent is an event of Holds.
+type HoldEvent struct {
+	Kind     HoldEventKind
+	Duration time.Duration // how long the hotkey was held so far
+}
+
+// repeatDebounce is how long a release waits for a press that makes it
+// part of an auto-repeat release/press pair, as X11 reports them.
+const repeatDebounce = 30 * time.Millisecond
+
+// Holds returns a channel that receives the presses of the hotkey
+// classified by their duration, together with a function that stops the
+// detection and closes the channel. A press that is released before
+// threshold is a ShortPress; otherwise HoldStart is sent once the hotkey
+// is held for threshold, and HoldEnd on release, but no ShortPress.
+//
+// Auto-repeat does not cut a hold short: a release followed by a press
+// within a few milliseconds, and a press while the hotkey is held, are
+// taken as auto-repeat. Releases are therefore reported that much later.
+func (hk *Hotkey) Holds(threshold time.Duration) (<-chan HoldEvent, func()) {
+	events, unsubscribe := hk.Subscribe(Buffer{})
+	in, out := newEventChan[HoldEvent]()
+	go func() {
+		defer close(in)
+		var (
+			down, holding         bool
+			pressedAt, releasedAt time.Time
+			hold, release         *time.Timer
+			held                  <-chan time.Time // fires at the threshold
+			released              <-chan time.Time // fires after the debounce
+		)
+		stop := func(t *time.Timer) {
+			if t != nil {
+				t.Stop()
+			}
+		}
+		defer func() {
+			stop(hold)
+			stop(release)
+		}()
+
+		for {
+			select {
+			case e, ok := <-events:
+				if !ok {
+					return
+				}
+				switch {
+				case e.Kind == EventKeydown && released != nil:
+					stop(release) // auto-repeat
+					released = nil
+					if !holding {
+						hold = time.NewTimer(threshold - time.Since(pressedAt))
+						held = hold.C
+					}
+				case e.Kind == EventKeydown && !down:
+					down, pressedAt = true, time.Now()
+					hold = time.NewTimer(threshold)
+					held = hold.C
+				case e.Kind == EventKeyup && down && released == nil:
+					// The threshold is not reached while the release is
+					// debounced, unless a repeat takes it back.
+					stop(hold)
+					held, releasedAt = nil, time.Now()
+					release = time.NewTimer(repeatDebounce)
+					released = release.C
+				}
+			case <-held:
+				holding, held = true, nil
+				in <- HoldEvent{Kind: HoldStart, Duration: time.Since(pressedAt)}
+			case <-released:
+				kind := ShortPress
+				if holding {
+					kind = HoldEnd
+				}
+				in <- HoldEvent{Kind: kind, Duration: releasedAt.Sub(pressedAt)}
+				down, holding, held, released = false, false, nil, nil
+			}
+		}
+	}()
+	return out, unsubscribe
+}
@@ -0,0 +1,187 @@
+// Copyright 2026 The golang.design Initiative Authors.
+// All rights reserved. Use of this source code is governed
+// by a MIT license that can be found in the LICENSE file.
+
+package hotkey
+
+import "sync"
+
+// Handler handles an event of a hotkey.
+type Handler func(Event)
+
+// Middleware wraps a Handler to add behaviour around it, such as logging,
+// tracing or Recover.
+type Middleware func(Handler) Handler
+
+// Dispatcher runs f, possibly on another goroutine or thread, for example
+// the UI thread of a GUI toolkit. It must run the functions it is given in
+// order.
+type Dispatcher func(f func())
+
+// handlers holds the handlers of a hotkey and the queue that feeds them.
+type handlers struct {
+	mu     sync.Mutex
+	down   chain
+	up     chain
+	repeat chain
+	mws    []Middleware
+	via    Dispatcher
+	in     chan<- Event // nil until the first handler is added
+	closed bool
+}
+
+// chain holds the handlers of one kind of event.
+type chain struct {
+	hs      []Handler // as added
+	wrapped []Handler // hs wrapped in the middlewares, see wrap
+}
+
+// OnDown adds h to the handlers called when the hotkey is pressed.
+//
+// Handlers are called one at a time, in the order the events happened, on
+// a delivery goroutine of the hotkey rather than on the platform event
+// loop, so a slow handler delays the following events but never the
+// detection of key presses. Handlers stay in place across Unregister and
+// Register; after Close, the events that are still queued are handled and
+// the delivery goroutine ends.
+func (hk *Hotkey) OnDown(h Handler) { hk.addHandler(&hk.handlers.down, h) }
+
+// OnUp adds h to the handlers called when the hotkey is released, like
+// OnDown.
+func (hk *Hotkey) OnUp(h Handler) { hk.addHandler(&hk.handlers.up, h) }
+
+// OnRepeat adds h to the handlers called when the held hotkey auto-repeats,
+// like OnDown. Repeats are only reported after ReportRepeats(true).
+func (hk *Hotkey) OnRepeat(h Handler) { hk.addHandler(&hk.handlers.repeat, h) }
+
+// Use appends middlewares to the chain that wraps every handler of the
+// hotkey. The first middleware is the outermost, so Use(a, b) calls a,
+// which calls b, which calls the handler.
+//
+// Every handler is wrapped once, when it is added or when Use is called,
+// so a middleware may keep state, such as a counter or a rate limit, in
+// its closure. Use wraps the handlers added before it anew, which starts
+// that state over.
+func (hk *Hotkey) Use(mws ...Middleware) {
+	hk.handlers.mu.Lock()
+	defer hk.handlers.mu.Unlock()
+	hk.handlers.mws = append(hk.handlers.mws, mws...)
+	for _, c := range []*chain{&hk.handlers.down, &hk.handlers.up, &hk.handlers.repeat} {
+		wrapped := make([]Handler, len(c.hs))
+		for i, h := range c.hs {
+			wrapped[i] = hk.handlers.wrap(h)
+		}
+		c.wrapped = wrapped
+	}
+}
+
+// Dispatch makes the handlers of the hotkey run through d, such as
+// MainThread, instead of directly on the delivery goroutine. All handlers
+// of an event run in a single call of d. A nil d restores the default.
+func (hk *Hotkey) Dispatch(d Dispatcher) {
+	hk.handlers.mu.Lock()
+	defer hk.handlers.mu.Unlock()
+	hk.handlers.via = d
+}
+
+func (hk *Hotkey) addHandler(c *chain, h Handler) {
+	hk.handlers.mu.Lock()
+	defer hk.handlers.mu.Unlock()
+	if hk.handlers.closed {
+		return
+	}
+	c.hs = append(c.hs, h)
+	c.wrapped = append(c.wrapped, hk.handlers.wrap(h))
+	if hk.handlers.in != nil {
+		return
+	}
+
+	in, out := newEventChan[Event]()
+	hk.handlers.in = in
+	go func() {
+		for e := range out {
+			hk.deliver(e, false)
+		}
+	}()
+}
+
+// wrap wraps h in the middlewares. The caller must hold hs.mu.
+func (hs *handlers) wrap(h Handler) Handler {
+	for i := len(hs.mws) - 1; i >= 0; i-- {
+		h = hs.mws[i](h)
+	}
+	return h
+}
+
+// queueHandlers passes e to the delivery goroutine, if there are handlers.
+func (hk *Hotkey) queueHandlers(e Event) {
+	hk.handlers.mu.Lock()
+	defer hk.handlers.mu.Unlock()
+	if hk.handlers.in != nil && !hk.handlers.closed {
+		hk.handlers.in <- e
+	}
+}
+
+// deliver calls the handlers of e, each wrapped in the middlewares. If wait
+// is set, it returns only once the dispatcher has run them.
+func (hk *Hotkey) deliver(e Event, wait bool) {
+	hk.handlers.mu.Lock()
+	var hs []Handler
+	switch e.Kind {
+	case EventKeydown:
+		hs = hk.handlers.down.wrapped
+	case EventKeyup:
+		hs = hk.handlers.up.wrapped
+	case EventRepeat:
+		hs = hk.handlers.repeat.wrapped
+	}
+	via := hk.handlers.via
+	hk.handlers.mu.Unlock()
+
+	run := func() {
+		for _, h := range hs {
+			h(e)
+		}
+	}
+	if via == nil {
+		run()
+		return
+	}
+	if !wait {
+		via(run)
+		return
+	}
+	done := make(chan struct{})
+	via(func() {
+		defer close(done)
+		run()
+	})
+	<-done
+}
+
+// closeHandlers ends the delivery goroutine once the queued events are
+// handled. It is called by Close, once no more events are sent.
+func (hk *Hotkey) closeHandlers() {
+	hk.handlers.mu.Lock()
+	defer hk.handlers.mu.Unlock()
+	hk.handlers.closed = true
+	if hk.handlers.in != nil {
+		close(hk.handlers.in)
+	}
+}
+
+// Recover returns a middleware that recovers from a panic in a handler and
+// passes the panic value and the event to report, so a failing handler
+// does not bring down the program. report may be nil to ignore panics.
+func Recover(report func(v any, e Event)) Middleware {
+	return func(next Handler) Handler {
+		return func(e Event) {
+			defer func() {
+				if v := recover(); v != nil && report != nil {
+					report(v, e)
+				}
+			}()
+			next(e)
+		}
+	}
+}
@@ -21,21 +21,43 @@
 //
 //   - On macOS, hotkeys are delivered through a CGEventTap, which requires
 //     the application to be trusted for Accessibility (Input Monitoring).
-//     Without that permission, Register returns an error. Grant it in
-//     System Settings → Privacy & Security → Accessibility.
+//     Without that permission, Register returns a ConflictError wrapping
+//     ErrPermissionDenied. Grant it in System Settings → Privacy & Security
+//     → Accessibility.
 //
-//   - On Linux (X11), when AutoRepeat is enabled in the X server, the
-//     Keyup is triggered automatically and continuously as Keydown continues.
+//   - A held hotkey that auto-repeats triggers Keydown and Keyup only once,
+//     on the press and on the release. On Linux (X11), this relies on XKB's
+//     detectable auto-repeat, or on filtering the release/press pairs of
+//     repeats. Use ReportRepeats to receive the repeats as EventRepeat,
+//     or SetRepeat to report them at a fixed delay and rate instead.
+//
+//   - Modifier-only hotkeys, made with NewModifierOnly from a physical key
+//     such as RCTL, are supported on Linux (X11) with libXi only. They
+//     listen to the raw key events of XInput instead of grabbing the key,
+//     so the modifier and its combinations keep working in other
+//     applications.
 //
 //   - On Linux (X11), some keys may be mapped to multiple Mod keys. To
 //     correctly register the key combination, one must use the correct
 //     underlying keycode combination. For example, a regular Ctrl+Alt+S
-//     might be registered as: Ctrl+Mod2+Mod4+S.
+//     might be registered as: Ctrl+Mod2+Mod4+S. The lock modifiers,
+//     CapsLock and NumLock (Mod2), are left out of the grab, so a hotkey
+//     fires whatever their state, and Ctrl+Mod2+S is the same hotkey as
+//     Ctrl+S.
 //
 //   - If this package did not include a desired key, one can always provide
 //     the keycode to the API. For example, if a key code is 0x15, then the
 //     corresponding key is `hotkey.Key(0x15)`.
 //
+//   - To bind a key by its position on the keyboard regardless of the
+//     layout, use NewPhysical with a PhysicalKey, e.g. one obtained from
+//     PhysicalKeyByName("TLDE") for the key left of 1. To bind the key
+//     that types a character on the current layout, use NewRune.
+//
+//   - Hotkeys of the same process with the same combination share a single
+//     registration with the system instead of conflicting, and all of them
+//     receive its events. See SetPropagation to deliver them by priority.
+//
 // THe following is a minimum example:
 //
 //	package main
@@ -69,89 +91,480 @@
 package hotkey
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // Errors reported by Register and Unregister. They are shared across all
-// platforms so the message is identical regardless of the operating system.
+// platforms so the message is identical regardless of the operating system,
+// and can be matched with errors.Is.
 var (
-	errAlreadyRegistered = errors.New("hotkey: already registered")
-	errNotRegistered     = errors.New("hotkey: not registered")
-	errRegisterFailed    = errors.New("hotkey: failed to register, the combination might already be taken by another application")
+	ErrAlreadyRegistered = errors.New("hotkey: already registered")
+	ErrNotRegistered     = errors.New("hotkey: not registered")
+	ErrRegisterFailed    = errors.New("hotkey: failed to register, the combination might already be taken by another application")
+	ErrClosed            = errors.New("hotkey: closed")
+
+	// ErrKeyNotOnKeyboard is returned when a key cannot be produced by the
+	// current keyboard, e.g. a keysym missing from the X11 keymap. The
+	// returned error wraps it and names the offending key.
+	ErrKeyNotOnKeyboard = errors.New("hotkey: key is not on the keyboard")
+
+	// ErrPermissionDenied is wrapped by the ConflictError returned on macOS
+	// when the application is not trusted for Accessibility (Input
+	// Monitoring).
+	ErrPermissionDenied = errors.New("hotkey: permission denied, grant the application Accessibility (Input Monitoring) permission")
+
+	// ErrUnsupported is returned by features that are not available on
+	// the current platform, such as capturing the whole keyboard outside
+	// of X11. It wraps errors.ErrUnsupported.
+	ErrUnsupported = fmt.Errorf("hotkey: %w on this platform", errors.ErrUnsupported)
 )
 
+// ConflictError is returned by Register when the operating system refuses
+// the combination. It wraps ErrRegisterFailed, and Err if set, so both can
+// be matched with errors.Is.
+type ConflictError struct {
+	Mods []Modifier
+	Key  Key
+
+	// Backend names the platform mechanism that refused the combination:
+	// "x11", "windows" or "darwin".
+	Backend string
+	// Code is the platform error code: the X error code (BadAccess) on
+	// X11 and the Win32 error code on Windows. It is zero on macOS.
+	Code int
+	// Err is the underlying platform error, if any.
+	Err error
+
+	temporary bool
+}
+
+func (e *ConflictError) Error() string {
+	s := fmt.Sprintf("%v: %v (%s", ErrRegisterFailed, combination(e.Mods, e.Key), e.Backend)
+	if e.Code != 0 {
+		s += fmt.Sprintf(" code %d", e.Code)
+	}
+	if e.Err != nil {
+		s += fmt.Sprintf(": %v", e.Err)
+	}
+	return s + ")"
+}
+
+func (e *ConflictError) Unwrap() []error {
+	if e.Err == nil {
+		return []error{ErrRegisterFailed}
+	}
+	return []error{ErrRegisterFailed, e.Err}
+}
+
+// Temporary reports whether the combination is held by another application
+// and registering it again may succeed once that application releases it.
+// It is false when retrying cannot help, such as a missing permission.
+func (e *ConflictError) Temporary() bool { return e.temporary }
+
 // Event represents a hotkey event
-type Event struct{}
+type Event struct {
+	Kind EventKind
+
+	stop *atomic.Bool // set by StopPropagation, see Prioritized
+}
+
+// EventKind tells what happened to a hotkey.
+type EventKind uint8
+
+// The kinds of events.
+const (
+	EventKeydown EventKind = iota + 1 // the hotkey was pressed
+	EventKeyup                        // the hotkey was released
+	EventRepeat                       // the held hotkey auto-repeats, see ReportRepeats
+)
+
+// String returns the name of the event kind.
+func (k EventKind) String() string {
+	switch k {
+	case EventKeydown:
+		return "keydown"
+	case EventKeyup:
+		return "keyup"
+	case EventRepeat:
+		return "repeat"
+	}
+	return fmt.Sprintf("EventKind(%d)", uint8(k))
+}
 
 // Hotkey is a combination of modifiers and key to trigger an event
 type Hotkey struct {
 	platformHotkey
 
-	mods []Modifier
-	key  Key
+	mu         sync.Mutex // guards platformHotkey and the fields below
+	mods       []Modifier
+	key        Key
+	physical   bool // key is a PhysicalKey rather than a Key
+	registered bool
+	closed     bool
+	done       chan struct{} // closed by unregister
+	binding    *binding      // the shared registration while registered
+	priority   int           // guarded by bindings, see SetPriority
 
+	// The channels are created by New and stay the same for the
+	// lifetime of the hotkey, so they need no locking. Only Close
+	// closes them.
 	keydownIn  chan<- Event
 	keydownOut <-chan Event
 	keyupIn    chan<- Event
 	keyupOut   <-chan Event
+
+	internal  bool       // Keydown and Keyup are never fed, see newInternal
+	emitMu    sync.Mutex // guards sealed against concurrent events
+	sealed    bool       // the channels are closed
+	repeats   atomic.Bool
+	repeater  atomic.Pointer[repeater] // set by SetRepeat
+	lmu       sync.Mutex               // serializes changes of listeners and guards subs
+	listeners atomic.Pointer[[]*listener]
+	subs      map[*subscription]struct{} // nil once closed
+	handlers  handlers
 }
 
+// listener receives every event of a hotkey, see listen.
+type listener struct{ deliver func(Event) }
+
 // New creates a new hotkey for the given modifiers and keycode.
-func New(mods []Modifier, key Key) *Hotkey {
-	keydownIn, keydownOut := newEventChan()
-	keyupIn, keyupOut := newEventChan()
+func New(mods []Modifier, key Key) *Hotkey { return newHotkey(mods, key, false) }
+
+func newHotkey(mods []Modifier, key Key, physical bool) *Hotkey {
+	keydownIn, keydownOut := newEventChan[Event]()
+	keyupIn, keyupOut := newEventChan[Event]()
 	hk := &Hotkey{
 		mods:       mods,
 		key:        key,
+		physical:   physical,
+		done:       make(chan struct{}),
+		subs:       map[*subscription]struct{}{},
 		keydownIn:  keydownIn,
 		keydownOut: keydownOut,
 		keyupIn:    keyupIn,
 		keyupOut:   keyupOut,
 	}
 
-	// Make sure the hotkey is unregistered when the created
-	// hotkey is garbage collected.
-	runtime.SetFinalizer(hk, func(x interface{}) {
-		hk := x.(*Hotkey)
-		hk.unregister()
+	// A registered hotkey is referenced by its binding, so it can only
+	// be garbage collected once unregistered. Nothing sends on the channels
+	// anymore at that point, and closing them ends their buffering
+	// goroutines.
+	runtime.SetFinalizer(hk, func(hk *Hotkey) {
 		close(hk.keydownIn)
 		close(hk.keyupIn)
 	})
 	return hk
 }
 
+// newInternal creates a hotkey that is only consumed through listen, so
+// that its Keydown and Keyup channels, which nobody reads, are not fed.
+func newInternal(mods []Modifier, key Key, physical bool) *Hotkey {
+	hk := newHotkey(mods, key, physical)
+	hk.internal = true
+	return hk
+}
+
 // Register registers a combination of hotkeys. If the hotkey has
 // registered. This function will invalidates the old registration
 // and overwrites its callback.
-func (hk *Hotkey) Register() error { return hk.register() }
+func (hk *Hotkey) Register() error {
+	hk.mu.Lock()
+	defer hk.mu.Unlock()
+	if hk.closed {
+		return ErrClosed
+	}
+	if hk.registered {
+		return ErrAlreadyRegistered
+	}
+	if hk.modifierOnly() && !modifierOnlySupported {
+		return ErrUnsupported
+	}
+	if hk.physical && PhysicalKey(hk.key) == AnyKey && !anyKeySupported {
+		return ErrUnsupported
+	}
+	b, err := acquire(hk, hk.mods, hk.key, hk.physical)
+	if err != nil {
+		return err
+	}
+	hk.binding, hk.registered = b, true
+	select {
+	case <-hk.done:
+		hk.done = make(chan struct{})
+	default:
+	}
+	track(hk)
+	return nil
+}
+
+// Backoff bounds of RegisterEventually.
+const (
+	retryMin = 100 * time.Millisecond
+	retryMax = 5 * time.Second
+)
+
+// RegisterEventually registers the hotkey in the background and keeps
+// retrying, with exponential backoff, while the combination is held by
+// another application (see ConflictError.Temporary). This is useful when,
+// for example, a daemon starts before the previous owner of its shortcut
+// has exited.
+//
+// The returned channel receives exactly one value: nil once the hotkey is
+// acquired, ctx.Err() if ctx is done first, or any other registration
+// error, which is not retried.
+func (hk *Hotkey) RegisterEventually(ctx context.Context) <-chan error {
+	acquired := make(chan error, 1)
+	go func() {
+		delay := retryMin
+		for {
+			if err := ctx.Err(); err != nil {
+				acquired <- err
+				return
+			}
+			err := hk.Register()
+			var cerr *ConflictError
+			if !errors.As(err, &cerr) || !cerr.Temporary() {
+				acquired <- err
+				return
+			}
+
+			t := time.NewTimer(delay)
+			select {
+			case <-ctx.Done():
+				t.Stop()
+			case <-t.C:
+			}
+			delay = min(2*delay, retryMax)
+		}
+	}()
+	return acquired
+}
+
+// Rebind changes the combination of the hotkey to mods and key. The
+// Keydown and Keyup channels are kept, so listeners do not notice the
+// change. If the hotkey is registered, the new combination is registered
+// before the old one is released: on failure the error is returned and the
+// hotkey stays registered with its old combination.
+func (hk *Hotkey) Rebind(mods []Modifier, key Key) error {
+	hk.mu.Lock()
+	defer hk.mu.Unlock()
+	if hk.is(mods, key) {
+		return nil // registering it again would conflict with itself
+	}
+	if hk.registered {
+		b, err := acquire(hk, mods, key, false)
+		if err != nil {
+			return err
+		}
+		hk.binding.release(hk)
+		hk.stopRepeat()
+		hk.binding = b
+	}
+	hk.mods, hk.key, hk.physical = mods, key, false
+	return nil
+}
 
-// Keydown returns a channel that receives a signal when the hotkey is triggered.
+// Keydown returns a channel that receives a signal when the hotkey is
+// triggered. The channel is the same for the lifetime of the hotkey, also
+// across Unregister and Register, and is only closed by Close; use Done to
+// stop waiting on it when the hotkey is unregistered. Every event is
+// received once, so several consumers should use Subscribe instead.
 func (hk *Hotkey) Keydown() <-chan Event { return hk.keydownOut }
 
-// Keyup returns a channel that receives a signal when the hotkey is released.
+// Keyup returns a channel that receives a signal when the hotkey is
+// released. Like Keydown, it is never replaced and only closed by Close.
 func (hk *Hotkey) Keyup() <-chan Event { return hk.keyupOut }
 
+// Done returns a channel that is closed when the hotkey is unregistered.
+// After the hotkey is registered again, Done returns a new channel.
+func (hk *Hotkey) Done() <-chan struct{} {
+	hk.mu.Lock()
+	defer hk.mu.Unlock()
+	return hk.done
+}
+
 // Unregister unregisters the hotkey.
 func (hk *Hotkey) Unregister() error {
-	err := hk.unregister()
-	if err != nil {
-		return err
+	hk.mu.Lock()
+	defer hk.mu.Unlock()
+	if !hk.registered {
+		return ErrNotRegistered
 	}
+	hk.release()
+	return nil
+}
 
-	// Reset a new event channel.
+// Close unregisters the hotkey if it is registered and closes its Keydown
+// and Keyup channels. A closed hotkey cannot be registered again. Close is
+// idempotent and always returns nil; it implements io.Closer so a hotkey
+// can be cleaned up with a deferred Close instead of relying on the
+// garbage collector.
+func (hk *Hotkey) Close() error {
+	hk.mu.Lock()
+	defer hk.mu.Unlock()
+	if hk.closed {
+		return nil
+	}
+	if hk.registered {
+		hk.release()
+	}
+	hk.closed = true
+	runtime.SetFinalizer(hk, nil)
+	hk.emitMu.Lock()
+	hk.sealed = true
 	close(hk.keydownIn)
 	close(hk.keyupIn)
-	hk.keydownIn, hk.keydownOut = newEventChan()
-	hk.keyupIn, hk.keyupOut = newEventChan()
+	hk.emitMu.Unlock()
+	hk.closeHandlers()
+	hk.closeSubscriptions()
 	return nil
 }
 
+// release unregisters a registered hotkey. The caller must hold hk.mu.
+func (hk *Hotkey) release() {
+	hk.binding.release(hk)
+	hk.stopRepeat()
+	hk.binding, hk.registered = nil, false
+	close(hk.done)
+	untrack(hk)
+}
+
+// emit delivers an event from the platform event loop.
+func (hk *Hotkey) emit(kind EventKind) { hk.send(Event{Kind: kind}) }
+
+// ReportRepeats sets whether the auto-repeats of the hotkey while it is
+// held are reported as EventRepeat to its subscribers, listeners and
+// OnRepeat handlers. Repeats are never sent on Keydown and Keyup, which
+// receive a single event per press and release. They are not reported by
+// default.
+func (hk *Hotkey) ReportRepeats(report bool) { hk.repeats.Store(report) }
+
+// send delivers e to the Keydown or Keyup channel, the listeners and the
+// handlers of hk, without waiting for any of them. Events sent after Close
+// are dropped.
+func (hk *Hotkey) send(e Event) {
+	if hk.accept(e) {
+		hk.post(e)
+	}
+}
+
+// post is send without the filtering of repeats.
+func (hk *Hotkey) post(e Event) {
+	hk.emitMu.Lock()
+	defer hk.emitMu.Unlock()
+	if hk.notify(e) {
+		hk.queueHandlers(e)
+	}
+}
+
+// sendAndWait is send, but it runs the handlers of hk and waits for them.
+func (hk *Hotkey) sendAndWait(e Event) {
+	if !hk.accept(e) {
+		return
+	}
+	hk.emitMu.Lock()
+	ok := hk.notify(e)
+	hk.emitMu.Unlock()
+	if ok {
+		hk.deliver(e, true)
+	}
+}
+
+// notify delivers e to the Keydown or Keyup channel and the listeners. It
+// reports false if hk is closed. The caller must hold hk.emitMu.
+func (hk *Hotkey) notify(e Event) bool {
+	if hk.sealed {
+		return false
+	}
+	switch {
+	case hk.internal:
+	case e.Kind == EventKeydown:
+		hk.keydownIn <- e
+	case e.Kind == EventKeyup:
+		hk.keyupIn <- e
+	}
+	if ls := hk.listeners.Load(); ls != nil {
+		for _, l := range *ls {
+			l.deliver(e)
+		}
+	}
+	return true
+}
+
+// listen makes emit call deliver with every event of the hotkey, in order,
+// on the platform event loop, so deliver must not block. It returns a
+// function that removes deliver again; an event that is being emitted
+// concurrently may still reach it.
+func (hk *Hotkey) listen(deliver func(Event)) (remove func()) {
+	hk.lmu.Lock()
+	defer hk.lmu.Unlock()
+	return hk.listenLocked(deliver)
+}
+
+// listenLocked is listen for a caller that holds hk.lmu.
+func (hk *Hotkey) listenLocked(deliver func(Event)) (remove func()) {
+	l := &listener{deliver: deliver}
+	var ls []*listener
+	if old := hk.listeners.Load(); old != nil {
+		ls = append(ls, *old...)
+	}
+	ls = append(ls, l)
+	hk.listeners.Store(&ls)
+
+	return func() {
+		hk.lmu.Lock()
+		defer hk.lmu.Unlock()
+		old := hk.listeners.Load()
+		if old == nil {
+			return
+		}
+		var ls []*listener
+		for _, x := range *old {
+			if x != l {
+				ls = append(ls, x)
+			}
+		}
+		hk.listeners.Store(&ls)
+	}
+}
+
 // String returns a string representation of the hotkey.
 func (hk *Hotkey) String() string {
-	s := fmt.Sprintf("%v", hk.key)
-	for _, mod := range hk.mods {
+	hk.mu.Lock()
+	defer hk.mu.Unlock()
+	return hk.string()
+}
+
+// string is String. The caller must hold hk.mu.
+func (hk *Hotkey) string() string {
+	if hk.modifierOnly() {
+		return combination(nil, Key(hk.physicalKey()))
+	}
+	return combination(hk.mods, hk.key)
+}
+
+// is reports whether hk already is the combination of mods and key.
+// The caller must hold hk.mu.
+func (hk *Hotkey) is(mods []Modifier, key Key) bool {
+	var a, b Modifier
+	for _, m := range hk.mods {
+		a |= m
+	}
+	for _, m := range mods {
+		b |= m
+	}
+	return !hk.physical && hk.key == key && a == b
+}
+
+// combination formats a key and its modifiers.
+func combination(mods []Modifier, key Key) string {
+	s := fmt.Sprintf("%v", key)
+	for _, mod := range mods {
 		s += fmt.Sprintf("+%v", mod)
 	}
 	return s
@@ -159,11 +572,11 @@ func (hk *Hotkey) String() string {
 
 // newEventChan returns a sender and a receiver of a buffered channel
 // with infinite capacity.
-func newEventChan() (chan<- Event, <-chan Event) {
-	in, out := make(chan Event), make(chan Event)
+func newEventChan[T any]() (chan<- T, <-chan T) {
+	in, out := make(chan T), make(chan T)
 
 	go func() {
-		var q []Event
+		var q []T
 
 		for {
 			e, ok := <-in
@@ -175,7 +588,8 @@ func newEventChan() (chan<- Event, <-chan Event) {
 			for len(q) > 0 {
 				select {
 				case out <- q[0]:
-					q[0] = Event{}
+					var zero T
+					q[0] = zero
 					q = q[1:]
 				case e, ok := <-in:
 					if ok {
@@ -10,7 +10,7 @@ package hotkey
 
 /*
 #cgo CFLAGS: -x objective-c
-#cgo LDFLAGS: -framework Cocoa -framework CoreGraphics -framework ApplicationServices
+#cgo LDFLAGS: -framework Cocoa -framework CoreGraphics -framework ApplicationServices -framework Carbon
 #include <stdint.h>
 #import <Cocoa/Cocoa.h>
 
@@ -23,12 +23,13 @@ extern void keyupCallback(uintptr_t handle);
 void* registerTap(uintptr_t handle, int isMedia, int code, uint64_t flags);
 void unregisterTap(void* tap);
 int isAXTrusted();
+int lookupRune(uint16_t ch, uint32_t *mods);
 */
 import "C"
 import (
 	"errors"
+	"fmt"
 	"runtime/cgo"
-	"sync"
 	"unsafe"
 )
 
@@ -38,10 +39,8 @@ import (
 // application to be trusted for Accessibility (Input Monitoring). Register
 // returns an error when that permission is missing.
 type platformHotkey struct {
-	mu         sync.Mutex
-	registered bool
-	tap        unsafe.Pointer
-	handle     cgo.Handle
+	tap    unsafe.Pointer
+	handle cgo.Handle
 }
 
 // CGEventFlags modifier masks (see CGEventTypes.h), mapped from the package's
@@ -53,23 +52,33 @@ const (
 	cgFlagCommand = 0x100000
 )
 
+// register installs an event tap for the hotkey. The caller must hold
+// hk.mu.
 func (hk *Hotkey) register() error {
-	hk.mu.Lock()
-	defer hk.mu.Unlock()
-	if hk.registered {
-		return errAlreadyRegistered
+	if err := hk.probe(); err != nil {
+		return err
 	}
 
+	h := cgo.NewHandle(hk)
+	tap, err := hk.installTap(h)
+	if err != nil {
+		h.Delete()
+		return err
+	}
+	hk.tap = tap
+	hk.handle = h
+	return nil
+}
+
+// installTap installs an event tap for the combination of hk that reports
+// its events to the Hotkey behind h.
+func (hk *Hotkey) installTap(h cgo.Handle) (unsafe.Pointer, error) {
 	var (
 		isMedia C.int
 		code    C.int
 		flags   C.uint64_t
 	)
-	if hk.key&mediaKeyBit != 0 {
-		if hk.key == KeyMediaStop {
-			// There is no NX_KEYTYPE for media stop on macOS.
-			return errors.New("hotkey: media stop is not available on macOS")
-		}
+	if !hk.physical && hk.key&mediaKeyBit != 0 {
 		isMedia = 1
 		code = C.int(hk.key &^ mediaKeyBit)
 	} else {
@@ -90,29 +99,89 @@ func (hk *Hotkey) register() error {
 		flags = f
 	}
 
-	h := cgo.NewHandle(hk)
 	tap := C.registerTap(C.uintptr_t(h), isMedia, code, flags)
 	if tap == nil {
-		h.Delete()
-		return errors.New("hotkey: failed to register, grant the application Accessibility (Input Monitoring) permission")
+		return nil, &ConflictError{Mods: hk.mods, Key: hk.key, Backend: "darwin", Err: errors.New("CGEventTapCreate failed")}
 	}
-	hk.tap = tap
-	hk.handle = h
-	hk.registered = true
-	return nil
+	return tap, nil
 }
 
-func (hk *Hotkey) unregister() error {
-	hk.mu.Lock()
-	defer hk.mu.Unlock()
-	if !hk.registered {
-		return errNotRegistered
+// probe checks that hk can be registered. Event taps do not conflict with
+// each other, so only the key and the Accessibility permission matter.
+func (hk *Hotkey) probe() error {
+	if !hk.physical && hk.key == KeyMediaStop {
+		// There is no NX_KEYTYPE for media stop on macOS.
+		return fmt.Errorf("%w: media stop is not available on macOS", ErrKeyNotOnKeyboard)
 	}
+	if !axTrusted() {
+		return &ConflictError{Mods: hk.mods, Key: hk.key, Backend: "darwin", Err: ErrPermissionDenied}
+	}
+	return nil
+}
+
+// unregister removes the event tap. The caller must hold hk.mu.
+func (hk *Hotkey) unregister() {
 	C.unregisterTap(hk.tap)
 	hk.tap = nil
 	hk.handle.Delete()
-	hk.registered = false
-	return nil
+}
+
+// alternativeModifiers are the modifiers added by Alternatives, see keyRows.
+var alternativeModifiers = []Modifier{ModShift, ModCtrl, ModOption, ModCmd}
+
+// virtualKeycodes maps XKB key names to macOS virtual keycodes (see
+// kVK_* in Events.h), laid out after an ANSI keyboard.
+var virtualKeycodes = map[string]PhysicalKey{
+	"ESC": 0x35, "AE01": 0x12, "AE02": 0x13, "AE03": 0x14, "AE04": 0x15,
+	"AE05": 0x17, "AE06": 0x16, "AE07": 0x1A, "AE08": 0x1C, "AE09": 0x19,
+	"AE10": 0x1D, "AE11": 0x1B, "AE12": 0x18, "BKSP": 0x33, "TAB": 0x30,
+	"AD01": 0x0C, "AD02": 0x0D, "AD03": 0x0E, "AD04": 0x0F, "AD05": 0x11,
+	"AD06": 0x10, "AD07": 0x20, "AD08": 0x22, "AD09": 0x1F, "AD10": 0x23,
+	"AD11": 0x21, "AD12": 0x1E, "RTRN": 0x24, "LCTL": 0x3B, "AC01": 0x00,
+	"AC02": 0x01, "AC03": 0x02, "AC04": 0x03, "AC05": 0x05, "AC06": 0x04,
+	"AC07": 0x26, "AC08": 0x28, "AC09": 0x25, "AC10": 0x29, "AC11": 0x27,
+	"TLDE": 0x32, "LFSH": 0x38, "BKSL": 0x2A, "AB01": 0x06, "AB02": 0x07,
+	"AB03": 0x08, "AB04": 0x09, "AB05": 0x0B, "AB06": 0x2D, "AB07": 0x2E,
+	"AB08": 0x2B, "AB09": 0x2F, "AB10": 0x2C, "RTSH": 0x3C, "KPMU": 0x43,
+	"LALT": 0x3A, "SPCE": 0x31, "CAPS": 0x39, "FK01": 0x7A, "FK02": 0x78,
+	"FK03": 0x63, "FK04": 0x76, "FK05": 0x60, "FK06": 0x61, "FK07": 0x62,
+	"FK08": 0x64, "FK09": 0x65, "FK10": 0x6D, "KP7": 0x59, "KP8": 0x5B,
+	"KP9": 0x5C, "KPSU": 0x4E, "KP4": 0x56, "KP5": 0x57, "KP6": 0x58,
+	"KPAD": 0x45, "KP1": 0x53, "KP2": 0x54, "KP3": 0x55, "KP0": 0x52,
+	"KPDL": 0x41, "LSGT": 0x0A, "FK11": 0x67, "FK12": 0x6F,
+}
+
+// resolveCombo returns the combo that register registers for mods+key. A
+// physical key already is a virtual keycode, so it is the same combo as
+// that Key.
+func resolveCombo(mods []Modifier, key Key, physical bool) (combo, error) {
+	return comboOf(mods, key, false), nil
+}
+
+func physicalKeyByName(name string) (PhysicalKey, error) {
+	code, ok := virtualKeycodes[name]
+	if !ok {
+		return 0, fmt.Errorf("hotkey: unknown key name %q", name)
+	}
+	return code, nil
+}
+
+func newRune(mods []Modifier, r rune) (*Hotkey, error) {
+	if r > 0xffff {
+		return nil, errNotTypable(r) // UCKeyTranslate yields UTF-16 units
+	}
+	var extra C.uint32_t
+	code := C.lookupRune(C.uint16_t(r), &extra)
+	if code < 0 {
+		return nil, errNotTypable(r)
+	}
+	mods = mods[:len(mods):len(mods)]
+	for _, m := range []Modifier{ModShift, ModOption} {
+		if Modifier(extra)&m != 0 {
+			mods = append(mods, m)
+		}
+	}
+	return New(mods, Key(code)), nil
 }
 
 // axTrusted reports whether the process is trusted for Accessibility (Input
@@ -123,13 +192,19 @@ func axTrusted() bool { return C.isAXTrusted() != 0 }
 //export keydownCallback
 func keydownCallback(h uintptr) {
 	hk := cgo.Handle(h).Value().(*Hotkey)
-	hk.keydownIn <- Event{}
+	hk.emit(EventKeydown)
 }
 
 //export keyupCallback
 func keyupCallback(h uintptr) {
 	hk := cgo.Handle(h).Value().(*Hotkey)
-	hk.keyupIn <- Event{}
+	hk.emit(EventKeyup)
+}
+
+//export repeatCallback
+func repeatCallback(h uintptr) {
+	hk := cgo.Handle(h).Value().(*Hotkey)
+	hk.emit(EventRepeat)
 }
 
 // Modifier represents a modifier.
@@ -8,10 +8,12 @@
 
 #include <stdint.h>
 #import <ApplicationServices/ApplicationServices.h>
+#import <Carbon/Carbon.h>
 #import <Cocoa/Cocoa.h>
 
 extern void keydownCallback(uintptr_t handle);
 extern void keyupCallback(uintptr_t handle);
+extern void repeatCallback(uintptr_t handle);
 
 // isAXTrusted reports whether the process is trusted for Accessibility
 // (Input Monitoring), which a keyboard event tap requires.
@@ -86,8 +88,10 @@ static CGEventRef tapCallback(CGEventTapProxy proxy, CGEventType type,
 		if (flags != t->flags) {
 			return event; // modifiers do not match this hotkey
 		}
-		// The tap repeats keyDown while the key is held; fire once.
-		if (!t->down) {
+		// The tap repeats keyDown while the key is held.
+		if (t->down) {
+			repeatCallback(t->handle);
+		} else {
 			t->down = 1;
 			keydownCallback(t->handle);
 		}
@@ -168,3 +172,51 @@ void unregisterTap(void* p) {
 	});
 	free(t);
 }
+
+// lookupRune finds a virtual keycode that types the UTF-16 unit ch on the
+// current keyboard layout, trying no modifiers, Shift, Option and
+// Shift+Option in that order. On success it stores the Carbon modifier mask
+// in mods and returns the keycode; it returns -1 if ch cannot be typed.
+int lookupRune(uint16_t ch, uint32_t *mods) {
+	__block int found = -1;
+	void (^lookup)(void) = ^{
+		TISInputSourceRef src = TISCopyCurrentKeyboardLayoutInputSource();
+		if (src == NULL) {
+			return;
+		}
+		CFDataRef data = (CFDataRef)TISGetInputSourceProperty(
+			src, kTISPropertyUnicodeKeyLayoutData);
+		if (data == NULL) {
+			CFRelease(src);
+			return;
+		}
+		const UCKeyboardLayout *layout =
+			(const UCKeyboardLayout *)CFDataGetBytePtr(data);
+		static const uint32_t states[] = {
+			0, shiftKey, optionKey, shiftKey | optionKey};
+		for (int s = 0; s < 4 && found < 0; s++) {
+			for (int code = 0; code < 128; code++) {
+				UInt32 dead = 0;
+				UniChar buf[4];
+				UniCharCount n = 0;
+				OSStatus err = UCKeyTranslate(
+					layout, code, kUCKeyActionDown,
+					(states[s] >> 8) & 0xff, LMGetKbdType(),
+					kUCKeyTranslateNoDeadKeysBit, &dead, 4, &n, buf);
+				if (err == noErr && n == 1 && buf[0] == ch) {
+					found = code;
+					*mods = states[s];
+					break;
+				}
+			}
+		}
+		CFRelease(src);
+	};
+	// The Text Input Sources API must be used on the main thread.
+	if ([NSThread isMainThread]) {
+		lookup();
+	} else {
+		dispatch_sync(dispatch_get_main_queue(), lookup);
+	}
+	return found;
+}
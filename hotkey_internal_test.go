@@ -0,0 +1,673 @@
+// Copyright 2026 The golang.design Initiative Authors.
+// All rights reserved. Use of this source code is governed
+// by a MIT license that can be found in the LICENSE file.
+
+package hotkey
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestConflictError(t *testing.T) {
+	cause := syscall.Errno(1409)
+	err := error(&ConflictError{
+		Mods: []Modifier{4, 1}, Key: 0x73,
+		Backend: "windows", Code: 1409, Err: cause, temporary: true,
+	})
+
+	if !errors.Is(err, ErrRegisterFailed) {
+		t.Errorf("errors.Is(%v, ErrRegisterFailed) = false, want true", err)
+	}
+	if !errors.Is(err, cause) {
+		t.Errorf("errors.Is(%v, cause) = false, want true", err)
+	}
+	var cerr *ConflictError
+	if !errors.As(err, &cerr) || !cerr.Temporary() {
+		t.Errorf("errors.As(%v) did not yield a temporary *ConflictError", err)
+	}
+	want := ErrRegisterFailed.Error() + ": 115+4+1 (windows code 1409: " + cause.Error() + ")"
+	if got := err.Error(); got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
+
+func TestCandidates(t *testing.T) {
+	const (
+		ctrl, shift, alt Modifier = 1, 2, 4
+		k1, k2, k3, k4   Key      = 1, 2, 3, 4
+	)
+	rows := [][]Key{{9, 8}, {k1, k2, k3, k4}}
+	got := candidates([]Modifier{ctrl}, k2, []Modifier{ctrl, shift, alt}, rows)
+	want := []Combination{
+		{Mods: []Modifier{ctrl, shift}, Key: k2},
+		{Mods: []Modifier{ctrl, alt}, Key: k2},
+		{Mods: []Modifier{ctrl}, Key: k1},
+		{Mods: []Modifier{ctrl}, Key: k3},
+		{Mods: []Modifier{ctrl}, Key: k4},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("candidates() = %v, want %v", got, want)
+	}
+}
+
+func TestRegisterError(t *testing.T) {
+	conflict := &ConflictError{Mods: []Modifier{4}, Key: 0x73, Backend: "x11", Code: 10, temporary: true}
+	err := error(&RegisterError{
+		Failed: []*Hotkey{New(nil, 1), New(nil, 2)},
+		Errs:   []error{conflict, ErrKeyNotOnKeyboard},
+	})
+
+	if !errors.Is(err, ErrRegisterFailed) || !errors.Is(err, ErrKeyNotOnKeyboard) {
+		t.Errorf("%v does not match every listed error", err)
+	}
+	var cerr *ConflictError
+	if !errors.As(err, &cerr) || cerr != conflict {
+		t.Errorf("errors.As(%v) did not yield the listed *ConflictError", err)
+	}
+	want := "hotkey: 2 hotkeys failed to register: " + conflict.Error() + "; " + ErrKeyNotOnKeyboard.Error()
+	if got := err.Error(); got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
+
+// TestListen verifies that listeners receive the emitted events in order
+// and stop receiving them once removed.
+func TestListen(t *testing.T) {
+	hk := newHotkey(nil, 1, false)
+	defer hk.Close()
+
+	keydown := hk.Keydown()
+	var got []EventKind
+	remove := hk.listen(func(e Event) { got = append(got, e.Kind) })
+	hk.emit(EventKeydown)
+	hk.emit(EventKeyup)
+	remove()
+	hk.emit(EventKeydown)
+
+	want := []EventKind{EventKeydown, EventKeyup}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("listener got %v, want %v", got, want)
+	}
+	if e := <-keydown; e.Kind != EventKeydown {
+		t.Errorf("Keydown() received %v, want %v", e.Kind, EventKeydown)
+	}
+}
+
+// TestHandlers verifies that handlers are called in order, wrapped in the
+// middlewares, and that Recover keeps the delivery goroutine alive.
+func TestHandlers(t *testing.T) {
+	hk := newHotkey(nil, 1, false)
+
+	var (
+		got       []string
+		recovered []any
+		done      = make(chan struct{})
+	)
+	hk.Use(Recover(func(v any, e Event) { recovered = append(recovered, v) }))
+	hk.Use(func(next Handler) Handler {
+		return func(e Event) {
+			got = append(got, "mw:"+e.Kind.String())
+			next(e)
+		}
+	})
+	hk.OnDown(func(e Event) { panic("boom") })
+	hk.OnDown(func(e Event) { got = append(got, "down") })
+	hk.OnUp(func(e Event) { got = append(got, "up"); close(done) })
+
+	hk.emit(EventKeydown)
+	hk.emit(EventKeyup)
+	<-done
+	hk.Close()
+
+	want := []string{"mw:keydown", "mw:keydown", "down", "mw:keyup", "up"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("handlers got %v, want %v", got, want)
+	}
+	if !reflect.DeepEqual(recovered, []any{"boom"}) {
+		t.Errorf("Recover reported %v, want [boom]", recovered)
+	}
+}
+
+// TestDispatch verifies that handlers run through the dispatcher.
+func TestDispatch(t *testing.T) {
+	hk := newHotkey(nil, 1, false)
+	defer hk.Close()
+
+	dispatched := make(chan func(), 1)
+	hk.Dispatch(func(f func()) { dispatched <- f })
+	called := false
+	hk.OnDown(func(e Event) { called = true })
+
+	hk.emit(EventKeydown)
+	f := <-dispatched
+	if called {
+		t.Fatalf("handler ran before it was dispatched")
+	}
+	f()
+	if !called {
+		t.Errorf("dispatched function did not run the handler")
+	}
+}
+
+// TestSubscribe verifies that every subscriber receives each event,
+// following its buffer policy, until it unsubscribes.
+func TestSubscribe(t *testing.T) {
+	hk := newHotkey(nil, 1, false)
+
+	all, unsubscribe := hk.Subscribe(Buffer{})
+	newest, _ := hk.Subscribe(Buffer{Size: 1, Overflow: DropNewest})
+	oldest, _ := hk.Subscribe(Buffer{Size: 1, Overflow: DropOldest})
+
+	hk.emit(EventKeydown)
+	hk.emit(EventKeyup)
+	unsubscribe()
+	hk.emit(EventKeydown)
+
+	var got []EventKind
+	for e := range all {
+		got = append(got, e.Kind)
+	}
+	if want := []EventKind{EventKeydown, EventKeyup}; !reflect.DeepEqual(got, want) {
+		t.Errorf("unlimited subscriber got %v, want %v", got, want)
+	}
+	if e := <-newest; e.Kind != EventKeydown {
+		t.Errorf("DropNewest subscriber got %v, want %v", e.Kind, EventKeydown)
+	}
+	if e := <-oldest; e.Kind != EventKeydown {
+		t.Errorf("DropOldest subscriber got %v, want the last %v", e.Kind, EventKeydown)
+	}
+
+	hk.Close()
+	if _, ok := <-newest; ok {
+		t.Errorf("Close did not end the subscription")
+	}
+}
+
+// TestPropagation verifies that a shared binding broadcasts events to all
+// of its hotkeys, or delivers them by priority until one stops them.
+func TestPropagation(t *testing.T) {
+	low, high, last := newHotkey(nil, 1, false), newHotkey(nil, 1, false), newHotkey(nil, 1, false)
+	defer low.Close()
+	defer high.Close()
+	defer last.Close()
+	high.priority = 1
+	b := &binding{joined: []*Hotkey{low, high, last}}
+	b.sort()
+	for _, hk := range b.joined {
+		hk.Keydown()
+	}
+
+	b.relay(Event{Kind: EventKeydown})
+	for _, hk := range b.joined {
+		if e := <-hk.Keydown(); e.Kind != EventKeydown {
+			t.Errorf("Keydown() received %v, want %v", e.Kind, EventKeydown)
+		}
+	}
+
+	var got []string
+	record := func(hk *Hotkey, name string, stop bool) {
+		hk.OnDown(func(e Event) {
+			got = append(got, name)
+			if stop {
+				e.StopPropagation()
+			}
+		})
+	}
+	record(low, "low", true)
+	record(high, "high", false)
+	record(last, "last", false)
+	b.prioritize(Event{Kind: EventKeydown})
+	if want := []string{"high", "low"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("prioritized handlers got %v, want %v", got, want)
+	}
+}
+
+// TestAll verifies that the iterator yields the events of a hotkey and
+// ends when it is unregistered, after the events queued before.
+func TestAll(t *testing.T) {
+	hk := newHotkey(nil, 1, false)
+	defer hk.Close()
+
+	got := make(chan EventKind)
+	go func() {
+		for e := range hk.All(context.Background()) {
+			got <- e.Kind
+		}
+		close(got)
+	}()
+	for hk.listeners.Load() == nil {
+		runtime.Gosched()
+	}
+
+	hk.emit(EventKeydown)
+	if k := <-got; k != EventKeydown {
+		t.Errorf("All() yielded %v, want %v", k, EventKeydown)
+	}
+	hk.emit(EventKeydown)
+	hk.emit(EventKeyup)
+	close(hk.done) // as Unregister does
+	var rest []EventKind
+	for k := range got {
+		rest = append(rest, k)
+	}
+	if want := []EventKind{EventKeydown, EventKeyup}; !reflect.DeepEqual(rest, want) {
+		t.Errorf("All() yielded %v before it ended, want %v", rest, want)
+	}
+}
+
+// TestSequence verifies that a sequence grabs its follow-up strokes only
+// while they are expected, reports its progress and aborts on timeout.
+func TestSequence(t *testing.T) {
+	k, c := Combination{Mods: []Modifier{1}, Key: 0x6b}, Combination{Mods: []Modifier{1}, Key: 0x63}
+	s := NewSequence(50*time.Millisecond, k, k, c)
+	defer s.Close()
+
+	var (
+		mu      sync.Mutex
+		grabbed = map[Key]func(){}
+	)
+	s.grab = func(c Combination, pressed func()) (func(), error) {
+		mu.Lock()
+		defer mu.Unlock()
+		grabbed[c.Key] = pressed
+		return func() {
+			mu.Lock()
+			defer mu.Unlock()
+			delete(grabbed, c.Key)
+		}, nil
+	}
+	press := func(key Key) {
+		mu.Lock()
+		pressed := grabbed[key]
+		mu.Unlock()
+		if pressed == nil {
+			t.Fatalf("%#x is not grabbed", key)
+		}
+		pressed()
+	}
+	next := func(want SequenceEvent) {
+		t.Helper()
+		if got := <-s.Events(); got != want {
+			t.Errorf("got %+v, want %+v", got, want)
+		}
+	}
+	if err := s.Register(); err != nil {
+		t.Fatalf("failed to register sequence: %v", err)
+	}
+
+	press(0x6b)
+	next(SequenceEvent{Kind: SequenceProgress, Step: 1})
+	press(0x6b)
+	next(SequenceEvent{Kind: SequenceProgress, Step: 2})
+	press(0x63)
+	next(SequenceEvent{Kind: SequenceMatched, Step: 3})
+	mu.Lock()
+	if _, ok := grabbed[0x63]; ok {
+		t.Errorf("the last stroke is still grabbed after the match")
+	}
+	mu.Unlock()
+
+	press(0x6b)
+	next(SequenceEvent{Kind: SequenceProgress, Step: 1})
+	next(SequenceEvent{Kind: SequenceAborted, Step: 1, Err: ErrSequenceTimeout})
+}
+
+// fakeKeyboard is a keyboard capture that yields the given events.
+type fakeKeyboard struct{ events chan KeyEvent }
+
+func (k *fakeKeyboard) next(ctx context.Context) (KeyEvent, error) {
+	select {
+	case <-ctx.Done():
+		return KeyEvent{}, ctx.Err()
+	case e := <-k.events:
+		return e, nil
+	}
+}
+
+func (k *fakeKeyboard) close() {}
+
+// TestLeader verifies that a leader reports the key pressed after it, and
+// cancels on Escape and on timeout.
+func TestLeader(t *testing.T) {
+	kb := &fakeKeyboard{events: make(chan KeyEvent, 2)}
+	l := NewLeader(nil, 1, 50*time.Millisecond)
+	l.capture = func(ctx context.Context) (keyboard, error) { return kb, nil }
+	defer l.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	presses := make(chan struct{})
+	l.stopped = make(chan struct{})
+	go l.run(ctx, presses)
+	defer func() {
+		cancel()
+		<-l.stopped
+	}()
+
+	a := KeyEvent{Kind: EventKeydown, Key: 0x61, Text: "a"}
+	for _, tt := range []struct {
+		events []KeyEvent
+		want   LeaderEvent
+	}{
+		{[]KeyEvent{{Kind: EventKeyup, Key: 1}, a}, LeaderEvent{Kind: LeaderKey, Press: a}},
+		{[]KeyEvent{{Kind: EventKeydown, Key: keyEscape}}, LeaderEvent{Kind: LeaderCanceled}},
+		{nil, LeaderEvent{Kind: LeaderCanceled, Err: context.DeadlineExceeded}},
+	} {
+		for _, e := range tt.events {
+			kb.events <- e
+		}
+		presses <- struct{}{}
+		if e := <-l.Events(); e.Kind != LeaderActive {
+			t.Fatalf("got %v, want %v", e.Kind, LeaderActive)
+		}
+		if got := <-l.Events(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("got %+v, want %+v", got, tt.want)
+		}
+	}
+}
+
+// TestTaps verifies that presses in quick succession are counted as one
+// series, which ends after the interval or with the maximum count.
+func TestTaps(t *testing.T) {
+	hk := newHotkey(nil, 1, false)
+	defer hk.Close()
+
+	taps, stop := hk.Taps(50*time.Millisecond, 0)
+	triples, stopTriples := hk.Taps(time.Hour, 3)
+	defer stopTriples()
+	for range 3 {
+		hk.emit(EventKeydown)
+		hk.emit(EventKeyup)
+	}
+	if tap := <-triples; tap.Count != 3 {
+		t.Errorf("got %+v, want a triple press right away", tap)
+	}
+	if tap := <-taps; tap.Count != 3 {
+		t.Errorf("got %+v, want a triple press", tap)
+	}
+
+	hk.emit(EventKeydown)
+	if tap := <-taps; tap.Count != 1 {
+		t.Errorf("got %+v, want a single press", tap)
+	}
+	stop()
+	if _, ok := <-taps; ok {
+		t.Errorf("the channel is not closed after stop")
+	}
+}
+
+// TestHolds verifies that a short press is told apart from a hold, and that
+// auto-repeat release/press pairs do not end a hold.
+func TestHolds(t *testing.T) {
+	hk := newHotkey(nil, 1, false)
+	defer hk.Close()
+
+	// A release before the threshold is a short press, even when the
+	// threshold passes while the release is debounced.
+	short, stopShort := hk.Holds(repeatDebounce * 2 / 3)
+	hk.emit(EventKeydown)
+	hk.emit(EventKeyup)
+	if e := <-short; e.Kind != ShortPress {
+		t.Errorf("got %v, want %v", e.Kind, ShortPress)
+	}
+	stopShort()
+
+	// Auto-repeat does not cut a hold short.
+	const threshold = 50 * time.Millisecond
+	holds, stop := hk.Holds(threshold)
+	defer stop()
+	hk.emit(EventKeydown)
+	timeout := time.After(10 * time.Second)
+	for started := false; !started; {
+		select {
+		case e := <-holds:
+			if e.Kind != HoldStart {
+				t.Fatalf("got %v, want %v", e.Kind, HoldStart)
+			}
+			started = true
+		case <-time.After(5 * time.Millisecond):
+			hk.emit(EventKeyup) // auto-repeat
+			hk.emit(EventKeydown)
+		case <-timeout:
+			t.Fatal("no HoldStart")
+		}
+	}
+	hk.emit(EventKeyup)
+	if e := <-holds; e.Kind != HoldEnd || e.Duration < threshold {
+		t.Errorf("got %v after %v, want %v after the threshold", e.Kind, e.Duration, HoldEnd)
+	}
+}
+
+// TestReportRepeats verifies that repeats are only reported when asked for,
+// and never on the Keydown channel.
+func TestReportRepeats(t *testing.T) {
+	hk := newHotkey(nil, 1, false)
+	defer hk.Close()
+	keydown := hk.Keydown()
+	events, unsubscribe := hk.Subscribe(Buffer{})
+
+	hk.emit(EventKeydown)
+	hk.emit(EventRepeat)
+	hk.ReportRepeats(true)
+	hk.emit(EventRepeat)
+	hk.emit(EventKeyup)
+	unsubscribe()
+
+	var got []EventKind
+	for e := range events {
+		got = append(got, e.Kind)
+	}
+	if want := []EventKind{EventKeydown, EventRepeat, EventKeyup}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if e := <-keydown; e.Kind != EventKeydown {
+		t.Errorf("Keydown() received %v, want %v", e.Kind, EventKeydown)
+	}
+	select {
+	case e := <-keydown:
+		t.Errorf("Keydown() received %v, want nothing more", e.Kind)
+	default:
+	}
+}
+
+// TestSetRepeat verifies that SetRepeat reports repeats at its own pace
+// while the hotkey is held, ignoring those of the system, and that they
+// stop on release.
+func TestSetRepeat(t *testing.T) {
+	hk := newHotkey(nil, 1, false)
+	defer hk.Close()
+	const delay = 50 * time.Millisecond
+	hk.SetRepeat(delay, 5*time.Millisecond)
+	events, unsubscribe := hk.Subscribe(Buffer{})
+
+	pressed := time.Now()
+	hk.emit(EventKeydown)
+	hk.emit(EventRepeat) // from the system
+	if e := <-events; e.Kind != EventKeydown {
+		t.Fatalf("got %v, want %v", e.Kind, EventKeydown)
+	}
+	for i := range 3 {
+		e := <-events
+		if e.Kind != EventRepeat {
+			t.Fatalf("got %v, want %v", e.Kind, EventRepeat)
+		}
+		if i == 0 && time.Since(pressed) < delay {
+			t.Errorf("the first repeat came before the delay")
+		}
+	}
+	hk.emit(EventKeyup)
+	unsubscribe()
+
+	// Once the repeats are stopped, the release is the last event.
+	var last EventKind
+	for e := range events {
+		last = e.Kind
+	}
+	if last != EventKeyup {
+		t.Errorf("last event is %v, want %v", last, EventKeyup)
+	}
+
+	// Turned off, the system repeats are filtered as before.
+	hk.SetRepeat(0, 0)
+	events, unsubscribe = hk.Subscribe(Buffer{})
+	hk.emit(EventKeydown)
+	hk.emit(EventRepeat)
+	hk.emit(EventKeyup)
+	unsubscribe()
+	var got []EventKind
+	for e := range events {
+		got = append(got, e.Kind)
+	}
+	if want := []EventKind{EventKeydown, EventKeyup}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+// TestModifierOnly verifies that a modifier-only hotkey is told apart from
+// the other hotkeys, and that it cannot be registered where it is not
+// supported.
+func TestModifierOnly(t *testing.T) {
+	hk := NewModifierOnly(0x25)
+	defer hk.Close()
+	if !hk.modifierOnly() {
+		t.Error("NewModifierOnly made a regular hotkey")
+	}
+	if New([]Modifier{1}, 0x25).modifierOnly() || NewPhysical(nil, 0x25).modifierOnly() {
+		t.Error("a regular hotkey is modifier-only")
+	}
+	if comboOf(hk.mods, hk.key, hk.physical) == comboOf(nil, 0x25, true) {
+		t.Error("a modifier-only hotkey shares the combination of its key")
+	}
+	if got, want := hk.String(), fmt.Sprintf("%v", Key(0x25)); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	if !modifierOnlySupported {
+		if err := hk.Register(); !errors.Is(err, ErrUnsupported) {
+			t.Errorf("Register() = %v, want %v", err, ErrUnsupported)
+		}
+	}
+}
+
+// TestInternal verifies that an internal hotkey reaches its listeners but
+// does not queue its events for Keydown and Keyup.
+func TestInternal(t *testing.T) {
+	hk := newInternal(nil, 1, false)
+	var got []EventKind
+	hk.listen(func(e Event) { got = append(got, e.Kind) })
+	hk.emit(EventKeydown)
+	hk.emit(EventKeyup)
+	hk.Close()
+
+	if want := []EventKind{EventKeydown, EventKeyup}; !reflect.DeepEqual(got, want) {
+		t.Errorf("listener got %v, want %v", got, want)
+	}
+	for e := range hk.Keydown() {
+		t.Errorf("Keydown() received %v", e.Kind)
+	}
+	for e := range hk.Keyup() {
+		t.Errorf("Keyup() received %v", e.Kind)
+	}
+}
+
+// TestMiddlewareState verifies that a handler is wrapped once rather than
+// per event, so that a middleware can keep state in its closure.
+func TestMiddlewareState(t *testing.T) {
+	hk := newHotkey(nil, 1, false)
+	var counts []int
+	hk.Use(func(next Handler) Handler {
+		n := 0
+		return func(e Event) {
+			n++
+			counts = append(counts, n)
+			next(e)
+		}
+	})
+	done := make(chan struct{})
+	hk.OnUp(func(Event) { close(done) })
+	hk.OnDown(func(Event) {})
+	for range 3 {
+		hk.emit(EventKeydown)
+	}
+	hk.emit(EventKeyup)
+	<-done
+	hk.Close()
+
+	if want := []int{1, 2, 3, 1}; !reflect.DeepEqual(counts, want) {
+		t.Errorf("got counts %v, want %v", counts, want)
+	}
+}
+
+// TestSetRepeatRace verifies that a repeater replaced by SetRepeat while
+// a press is being accepted does not start repeating behind its back.
+func TestSetRepeatRace(t *testing.T) {
+	hk := newHotkey(nil, 1, false)
+	defer hk.Close()
+
+	// The press loads the repeater, which is replaced before it starts.
+	hk.SetRepeat(time.Millisecond, time.Millisecond)
+	r := hk.repeater.Load()
+	hk.SetRepeat(time.Millisecond, time.Millisecond)
+	r.start(hk)
+	r.mu.Lock()
+	running := r.stop != nil
+	r.mu.Unlock()
+	if running {
+		r.halt()
+		t.Error("a replaced repeater started")
+	}
+
+	for range 200 {
+		hk.SetRepeat(time.Millisecond, time.Millisecond)
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			hk.emit(EventKeydown)
+		}()
+		hk.SetRepeat(time.Millisecond, time.Millisecond)
+		wg.Wait()
+		hk.emit(EventKeyup)
+	}
+
+	events, unsubscribe := hk.Subscribe(Buffer{})
+	time.Sleep(20 * time.Millisecond)
+	unsubscribe()
+	for e := range events {
+		t.Fatalf("got %v after the release", e.Kind)
+	}
+}
+
+// TestAnyKeyUnsupported verifies that AnyKey cannot be registered where it
+// is not supported.
+func TestAnyKeyUnsupported(t *testing.T) {
+	if anyKeySupported {
+		t.Skip("AnyKey is supported")
+	}
+	hk := NewPhysical([]Modifier{1}, AnyKey)
+	defer hk.Close()
+	if err := hk.Register(); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Register() = %v, want %v", err, ErrUnsupported)
+	}
+}
+
+// TestBindingWithoutMembers verifies that an event that reaches a new
+// binding before any hotkey has joined it is dropped.
+func TestBindingWithoutMembers(t *testing.T) {
+	owner := newInternal(nil, 1, false)
+	defer owner.Close()
+	bindings.Lock()
+	b := newBinding(combo{key: 1}, owner)
+	bindings.Unlock()
+	defer close(b.queue)
+
+	owner.emit(EventKeydown)
+	b.propagation.Store(uint32(Prioritized))
+	owner.emit(EventKeyup)
+}
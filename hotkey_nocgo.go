@@ -16,11 +16,33 @@ type Modifier uint32
 // Key represents a key.
 type Key uint32
 
+// alternativeModifiers and keyRows define the candidates of Alternatives.
+var (
+	alternativeModifiers []Modifier
+	keyRows              [][]Key
+)
+
 func (hk *Hotkey) register() error {
 	panic("hotkey: cannot use when CGO_ENABLED=0")
 }
 
+func (hk *Hotkey) probe() error {
+	panic("hotkey: cannot use when CGO_ENABLED=0")
+}
+
 // unregister deregisteres a system hotkey.
-func (hk *Hotkey) unregister() error {
+func (hk *Hotkey) unregister() {
+	panic("hotkey: cannot use when CGO_ENABLED=0")
+}
+
+func resolveCombo(mods []Modifier, key Key, physical bool) (combo, error) {
+	return comboOf(mods, key, physical), nil
+}
+
+func physicalKeyByName(name string) (PhysicalKey, error) {
+	panic("hotkey: cannot use when CGO_ENABLED=0")
+}
+
+func newRune(mods []Modifier, r rune) (*Hotkey, error) {
 	panic("hotkey: cannot use when CGO_ENABLED=0")
 }
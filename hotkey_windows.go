@@ -11,37 +11,35 @@ package hotkey
 import (
 	"fmt"
 	"runtime"
-	"sync"
 	"sync/atomic"
+	"syscall"
 	"time"
 
 	"golang.design/x/hotkey/internal/win"
 )
 
 type platformHotkey struct {
-	mu         sync.Mutex
-	hotkeyId   uint64
-	registered bool
-	funcs      chan func()
-	canceled   chan struct{}
+	hotkeyId uint64
+	vk       Key // virtual-key code that was registered
+	funcs    chan func()
+	canceled chan struct{}
 }
 
 var hotkeyId uint64 // atomic
 
+// errorHotkeyAlreadyRegistered is the Win32 error RegisterHotKey fails with
+// when another application registered the combination.
+const errorHotkeyAlreadyRegistered syscall.Errno = 1409 // ERROR_HOTKEY_ALREADY_REGISTERED
+
 // register registers a system hotkey. It returns an error if
 // the registration is failed. This could be that the hotkey is
-// conflict with other hotkeys.
+// conflict with other hotkeys. The caller must hold hk.mu.
 func (hk *Hotkey) register() error {
-	hk.mu.Lock()
-	if hk.registered {
-		hk.mu.Unlock()
-		return errAlreadyRegistered
-	}
-
-	mod := uint8(0)
-	for _, m := range hk.mods {
-		mod = mod | uint8(m)
+	vk, err := hk.virtualKey()
+	if err != nil {
+		return err
 	}
+	hk.vk = vk
 
 	hk.hotkeyId = atomic.AddUint64(&hotkeyId, 1)
 	hk.funcs = make(chan func())
@@ -50,32 +48,94 @@ func (hk *Hotkey) register() error {
 
 	var (
 		ok   bool
-		err  error
 		done = make(chan struct{})
 	)
 	hk.funcs <- func() {
-		ok, err = win.RegisterHotKey(0, uintptr(hk.hotkeyId), uintptr(mod), uintptr(hk.key))
+		ok, err = win.RegisterHotKey(0, uintptr(hk.hotkeyId), uintptr(hk.modifiers()), uintptr(hk.vk))
 		done <- struct{}{}
 	}
 	<-done
 	if !ok {
 		close(hk.canceled)
-		hk.mu.Unlock()
-		return fmt.Errorf("%w: %v", errRegisterFailed, err)
+		return hk.conflict(err)
 	}
-	hk.registered = true
-	hk.mu.Unlock()
 	return nil
 }
 
-// unregister deregisteres a system hotkey.
-func (hk *Hotkey) unregister() error {
-	hk.mu.Lock()
-	defer hk.mu.Unlock()
-	if !hk.registered {
-		return errNotRegistered
+// probe registers hk and unregisters it right away on a thread of its own.
+func (hk *Hotkey) probe() error {
+	vk, err := hk.virtualKey()
+	if err != nil {
+		return err
+	}
+	errc := make(chan error)
+	go func() {
+		// A hotkey registered without a window belongs to the calling
+		// thread, which must not change until it is unregistered.
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+
+		id := uintptr(atomic.AddUint64(&hotkeyId, 1))
+		ok, err := win.RegisterHotKey(0, id, uintptr(hk.modifiers()), uintptr(vk))
+		if !ok {
+			errc <- hk.conflict(err)
+			return
+		}
+		win.UnregisterHotKey(0, id)
+		errc <- nil
+	}()
+	return <-errc
+}
+
+// modifiers returns the RegisterHotKey modifier flags of hk.
+func (hk *Hotkey) modifiers() uint8 {
+	mod := uint8(0)
+	for _, m := range hk.mods {
+		mod = mod | uint8(m)
+	}
+	return mod
+}
+
+// virtualKey returns the virtual-key code to register for hk.
+// RegisterHotKey only accepts virtual-key codes, so a physical key is
+// translated through the current keyboard layout here.
+func (hk *Hotkey) virtualKey() (Key, error) {
+	if !hk.physical {
+		if hk.key == 0 {
+			return 0, fmt.Errorf("%w: virtual-key code 0", ErrKeyNotOnKeyboard)
+		}
+		return hk.key, nil
+	}
+	vk := Key(win.MapVirtualKey(uint32(hk.key), win.MAPVK_VSC_TO_VK_EX))
+	if vk == 0 {
+		return 0, fmt.Errorf("%w: scancode %#x", ErrKeyNotOnKeyboard, uint32(hk.key))
+	}
+	return vk, nil
+}
+
+// resolveCombo returns the combo that register registers for mods+key: a
+// physical key is the virtual-key code it is translated to, so it shares
+// a binding with that virtual key.
+func resolveCombo(mods []Modifier, key Key, physical bool) (combo, error) {
+	vk, err := (&Hotkey{mods: mods, key: key, physical: physical}).virtualKey()
+	if err != nil {
+		return combo{}, err
+	}
+	return comboOf(mods, vk, false), nil
+}
+
+// conflict wraps an error returned by RegisterHotKey for hk.
+func (hk *Hotkey) conflict(err error) error {
+	cerr := &ConflictError{Mods: hk.mods, Key: hk.key, Backend: "windows", Err: err}
+	if errno, ok := err.(syscall.Errno); ok {
+		cerr.Code = int(errno)
+		cerr.temporary = errno == errorHotkeyAlreadyRegistered
 	}
+	return cerr
+}
 
+// unregister deregisteres a system hotkey. The caller must hold hk.mu.
+func (hk *Hotkey) unregister() {
 	done := make(chan struct{})
 	hk.funcs <- func() {
 		win.UnregisterHotKey(0, uintptr(hk.hotkeyId))
@@ -85,8 +145,6 @@ func (hk *Hotkey) unregister() error {
 	<-done
 
 	<-hk.canceled
-	hk.registered = false
-	return nil
 }
 
 const (
@@ -115,8 +173,8 @@ func (hk *Hotkey) handle() {
 				return
 			default:
 				// If the latest status is KeyDown, and AsyncKeyState is 0, consider key is up.
-				if win.GetAsyncKeyState(int(hk.key)) == 0 && isKeyDown {
-					hk.keyupIn <- Event{}
+				if win.GetAsyncKeyState(int(hk.vk)) == 0 && isKeyDown {
+					hk.emit(EventKeyup)
 					isKeyDown = false
 				}
 			}
@@ -128,7 +186,12 @@ func (hk *Hotkey) handle() {
 
 		switch msg.Message {
 		case wmHotkey:
-			hk.keydownIn <- Event{}
+			// The message repeats while the key is held.
+			if isKeyDown {
+				hk.emit(EventRepeat)
+				continue
+			}
+			hk.emit(EventKeydown)
 			isKeyDown = true
 		case wmQuit:
 			return
@@ -136,6 +199,62 @@ func (hk *Hotkey) handle() {
 	}
 }
 
+// alternativeModifiers are the modifiers added by Alternatives, see keyRows.
+var alternativeModifiers = []Modifier{ModShift, ModCtrl, ModAlt, ModWin}
+
+// scancodes maps XKB key names to PC/AT (set 1) scancodes. For these keys
+// the scancode equals the Linux evdev code.
+var scancodes = map[string]PhysicalKey{
+	"ESC": 0x01, "AE01": 0x02, "AE02": 0x03, "AE03": 0x04, "AE04": 0x05,
+	"AE05": 0x06, "AE06": 0x07, "AE07": 0x08, "AE08": 0x09, "AE09": 0x0A,
+	"AE10": 0x0B, "AE11": 0x0C, "AE12": 0x0D, "BKSP": 0x0E, "TAB": 0x0F,
+	"AD01": 0x10, "AD02": 0x11, "AD03": 0x12, "AD04": 0x13, "AD05": 0x14,
+	"AD06": 0x15, "AD07": 0x16, "AD08": 0x17, "AD09": 0x18, "AD10": 0x19,
+	"AD11": 0x1A, "AD12": 0x1B, "RTRN": 0x1C, "LCTL": 0x1D, "AC01": 0x1E,
+	"AC02": 0x1F, "AC03": 0x20, "AC04": 0x21, "AC05": 0x22, "AC06": 0x23,
+	"AC07": 0x24, "AC08": 0x25, "AC09": 0x26, "AC10": 0x27, "AC11": 0x28,
+	"TLDE": 0x29, "LFSH": 0x2A, "BKSL": 0x2B, "AB01": 0x2C, "AB02": 0x2D,
+	"AB03": 0x2E, "AB04": 0x2F, "AB05": 0x30, "AB06": 0x31, "AB07": 0x32,
+	"AB08": 0x33, "AB09": 0x34, "AB10": 0x35, "RTSH": 0x36, "KPMU": 0x37,
+	"LALT": 0x38, "SPCE": 0x39, "CAPS": 0x3A, "FK01": 0x3B, "FK02": 0x3C,
+	"FK03": 0x3D, "FK04": 0x3E, "FK05": 0x3F, "FK06": 0x40, "FK07": 0x41,
+	"FK08": 0x42, "FK09": 0x43, "FK10": 0x44, "KP7": 0x47, "KP8": 0x48,
+	"KP9": 0x49, "KPSU": 0x4A, "KP4": 0x4B, "KP5": 0x4C, "KP6": 0x4D,
+	"KPAD": 0x4E, "KP1": 0x4F, "KP2": 0x50, "KP3": 0x51, "KP0": 0x52,
+	"KPDL": 0x53, "LSGT": 0x56, "FK11": 0x57, "FK12": 0x58,
+}
+
+func physicalKeyByName(name string) (PhysicalKey, error) {
+	code, ok := scancodes[name]
+	if !ok {
+		return 0, fmt.Errorf("hotkey: unknown key name %q", name)
+	}
+	return code, nil
+}
+
+func newRune(mods []Modifier, r rune) (*Hotkey, error) {
+	if r > 0xffff {
+		return nil, errNotTypable(r) // VkKeyScan takes a single UTF-16 unit
+	}
+	ret := win.VkKeyScan(uint16(r))
+	if ret == -1 {
+		return nil, errNotTypable(r)
+	}
+
+	mods = mods[:len(mods):len(mods)]
+	shift := uint8(ret >> 8)
+	if shift&1 != 0 {
+		mods = append(mods, ModShift)
+	}
+	if shift&2 != 0 {
+		mods = append(mods, ModCtrl)
+	}
+	if shift&4 != 0 {
+		mods = append(mods, ModAlt)
+	}
+	return New(mods, Key(ret&0xff)), nil
+}
+
 // Modifier represents a modifier.
 // https://docs.microsoft.com/en-us/windows/win32/api/winuser/nf-winuser-registerhotkey
 type Modifier uint8
@@ -6,13 +6,19 @@
 
 //go:build linux || openbsd
 
+#include <X11/XKBlib.h>
 #include <X11/Xlib.h>
 #include <X11/Xutil.h>
+#include <X11/extensions/XI2.h>
+#include <dlfcn.h>
+#include <errno.h>
+#include <poll.h>
 #include <stdint.h>
-#include <string.h> // memset
+#include <string.h> // memset, strncmp
 
 extern void hotkeyDown(uintptr_t hkhandle);
 extern void hotkeyUp(uintptr_t hkhandle);
+extern void hotkeyRepeat(uintptr_t hkhandle);
 
 int displayTest() {
   Display *d = NULL;
@@ -106,16 +112,97 @@ int grabErrorHandler(Display *d, XErrorEvent *e) {
   return 0;
 }
 
-// grabHotkey grabs key on display d once per modifier mask in mods (the
+// keycodeByName returns the keycode of the key with the XKB key name (e.g.
+// "AE01", "TLDE") in the keymap of display d, following key aliases. Returns
+// 0 if no key has that name.
+int keycodeByName(Display *d, const char *name) {
+  XkbDescPtr xkb = XkbGetMap(d, 0, XkbUseCoreKbd);
+  if (xkb == NULL) {
+    return 0;
+  }
+  int keycode = 0;
+  if (XkbGetNames(d, XkbKeyNamesMask | XkbKeyAliasesMask, xkb) == Success &&
+      xkb->names != NULL && xkb->names->keys != NULL) {
+    for (int i = 0; i < xkb->names->num_key_aliases; i++) {
+      XkbKeyAliasPtr a = &xkb->names->key_aliases[i];
+      if (strncmp(a->alias, name, XkbKeyNameLength) == 0) {
+        name = a->real; // not NUL-terminated, only compared with strncmp
+        break;
+      }
+    }
+    for (int i = xkb->min_key_code; i <= xkb->max_key_code; i++) {
+      if (strncmp(xkb->names->keys[i].name, name, XkbKeyNameLength) == 0) {
+        keycode = i;
+        break;
+      }
+    }
+  }
+  XkbFreeKeyboard(xkb, 0, True);
+  return keycode;
+}
+
+// lookupKeysym finds a key that produces keysym in the current group of the
+// keymap of display d, preferring the lowest shift level. On success it
+// stores the modifier mask that selects that level in mods and returns the
+// keycode; it returns 0 if keysym cannot be typed.
+int lookupKeysym(Display *d, unsigned long keysym, unsigned int *mods) {
+  XkbDescPtr xkb =
+      XkbGetMap(d, XkbKeyTypesMask | XkbKeySymsMask, XkbUseCoreKbd);
+  if (xkb == NULL) {
+    return 0;
+  }
+  int group = 0;
+  XkbStateRec state;
+  if (XkbGetState(d, XkbUseCoreKbd, &state) == Success) {
+    group = state.group;
+  }
+
+  int keycode = 0, best = -1;
+  for (int kc = xkb->min_key_code; kc <= xkb->max_key_code && best != 0;
+       kc++) {
+    int ngroups = XkbKeyNumGroups(xkb, kc);
+    if (ngroups == 0) {
+      continue;
+    }
+    int g = group < ngroups ? group : 0;
+    XkbKeyTypePtr type = XkbKeyKeyType(xkb, kc, g);
+    for (int level = 0; level < XkbKeyGroupWidth(xkb, kc, g); level++) {
+      if (XkbKeySymEntry(xkb, kc, level, g) != keysym) {
+        continue;
+      }
+      // Level 0 needs no modifiers; any other level is selected by the
+      // modifiers of an active entry of the key type.
+      int reachable = level == 0;
+      unsigned int m = 0;
+      for (int i = 0; i < type->map_count && !reachable; i++) {
+        XkbKTMapEntryPtr e = &type->map[i];
+        if (e->active && e->level == level) {
+          m = e->mods.mask;
+          reachable = 1;
+        }
+      }
+      if (reachable && (best < 0 || level < best)) {
+        best = level;
+        keycode = kc;
+        *mods = m;
+      }
+      break;
+    }
+  }
+  XkbFreeKeyboard(xkb, 0, True);
+  return keycode;
+}
+
+// grabHotkey grabs keycode on display d once per modifier mask in mods (the
 // NumLock/CapsLock variants). It installs a temporary error handler and
 // XSyncs so that a BadAccess -- the combination is already grabbed by another
 // client -- is reported synchronously instead of terminating the program via
-// Xlib's default handler. Returns 0 on success, 1 if the combination is
-// unavailable. Callers must serialize this (it uses a process-global error
+// Xlib's default handler. Returns 0 on success, or the X error code raised
+// by the grab (BadAccess if the combination is unavailable), in which case
+// nothing stays grabbed. Callers must serialize this (it uses a process-global error
 // slot and swaps the process-global X error handler).
-int grabHotkey(Display *d, unsigned int *mods, int nmods, int key) {
+int grabHotkey(Display *d, unsigned int *mods, int nmods, int keycode) {
   lastGrabError = 0;
-  int keycode = XKeysymToKeycode(d, key);
   XErrorHandler old = XSetErrorHandler(grabErrorHandler);
   for (int i = 0; i < nmods; i++) {
     XGrabKey(d, keycode, mods[i], DefaultRootWindow(d), False, GrabModeAsync,
@@ -123,11 +210,11 @@ int grabHotkey(Display *d, unsigned int *mods, int nmods, int key) {
   }
   XSync(d, False); // force the server to deliver any grab error to our handler
   XSetErrorHandler(old);
-  if (lastGrabError == BadAccess) {
+  if (lastGrabError != 0) {
     for (int i = 0; i < nmods; i++) {
       XUngrabKey(d, keycode, mods[i], DefaultRootWindow(d));
     }
-    return 1;
+    return lastGrabError;
   }
   XSelectInput(d, DefaultRootWindow(d), KeyPressMask);
   return 0;
@@ -137,15 +224,41 @@ int grabHotkey(Display *d, unsigned int *mods, int nmods, int key) {
 // (see sendCancel) breaks the loop out of XNextEvent so an unregister can take
 // effect without waiting for the next keypress. The grab is established once
 // by grabHotkey and held until cleanupConnection.
+//
+// With detectable auto-repeat, a held key repeats KeyPress only and sends a
+// single KeyRelease when it is released. Servers without XKB send a
+// KeyRelease/KeyPress pair with the same timestamp for every repeat
+// instead, whose KeyRelease is dropped here. Either way, a KeyPress while
+// the key is down is reported as a repeat. Keys are tracked by keycode, as
+// a grab of AnyKey reports several.
 void waitHotkey(uintptr_t hkhandle, Display *d) {
-  XEvent ev;
+  Bool supported;
+  XkbSetDetectableAutoRepeat(d, True, &supported);
+
+  unsigned char down[32] = {0}; // the keys held, by keycode
+  XEvent ev, next;
   while (1) {
     XNextEvent(d, &ev);
+    unsigned int kc = ev.xkey.keycode;
     switch (ev.type) {
     case KeyPress:
+      if (down[kc / 8] & (1 << (kc % 8))) {
+        hotkeyRepeat(hkhandle);
+        continue;
+      }
+      down[kc / 8] |= 1 << (kc % 8);
       hotkeyDown(hkhandle);
       continue;
     case KeyRelease:
+      if (XEventsQueued(d, QueuedAfterReading) > 0) {
+        XPeekEvent(d, &next);
+        if (next.type == KeyPress && next.xkey.time == ev.xkey.time &&
+            next.xkey.keycode == kc)
+          continue;
+      }
+      if (!(down[kc / 8] & (1 << (kc % 8))))
+        continue; // not pressed since the loop started
+      down[kc / 8] &= ~(1 << (kc % 8));
       hotkeyUp(hkhandle);
       continue;
     case ClientMessage:
@@ -153,3 +266,184 @@ void waitHotkey(uintptr_t hkhandle, Display *d) {
     }
   }
 }
+
+// The parts of XInput2.h used here. libXi is loaded at run time by loadXI,
+// so that neither it nor its headers are needed to build the package.
+typedef struct {
+  int deviceid;
+  int mask_len;
+  unsigned char *mask;
+} XIEventMask;
+
+typedef struct {
+  int type;
+  unsigned long serial;
+  Bool send_event;
+  Display *display;
+  int extension;
+  int evtype;
+  Time time;
+  int deviceid;
+  int sourceid;
+  int detail;
+  int flags;
+  // the valuators follow, which are not used
+} XIRawEvent;
+
+static Status (*xiQueryVersion)(Display *d, int *major, int *minor);
+static int (*xiSelectEvents)(Display *d, Window w, XIEventMask *masks,
+                             int n);
+
+// loadXI loads libXi and returns 0, or -1 if it is not installed. It must
+// be called once, before the other functions that use XInput.
+int loadXI() {
+  void *lib = dlopen("libXi.so.6", RTLD_NOW | RTLD_LOCAL);
+  if (lib == NULL)
+    lib = dlopen("libXi.so", RTLD_NOW | RTLD_LOCAL);
+  if (lib == NULL)
+    return -1;
+  xiQueryVersion = dlsym(lib, "XIQueryVersion");
+  xiSelectEvents = dlsym(lib, "XISelectEvents");
+  if (xiQueryVersion == NULL || xiSelectEvents == NULL)
+    return -1;
+  return 0;
+}
+
+// selectRawEvents selects the raw key and button events of all devices on
+// the root window of d. Since XInput 2.1 they are reported regardless of
+// grabs, so nothing needs to be grabbed. It returns the major opcode of
+// XInput, or -1 if the X server does not support XInput 2.1.
+int selectRawEvents(Display *d) {
+  int opcode, event, error;
+  if (!XQueryExtension(d, "XInputExtension", &opcode, &event, &error))
+    return -1;
+  int major = 2, minor = 1;
+  if (xiQueryVersion(d, &major, &minor) != Success ||
+      (major == 2 && minor < 1))
+    return -1;
+
+  unsigned char bits[XIMaskLen(XI_LASTEVENT)] = {0};
+  XISetMask(bits, XI_RawKeyPress);
+  XISetMask(bits, XI_RawKeyRelease);
+  XISetMask(bits, XI_RawButtonPress);
+  XISetMask(bits, XI_RawButtonRelease);
+  XIEventMask mask = {XIAllMasterDevices, sizeof(bits), bits};
+  xiSelectEvents(d, DefaultRootWindow(d), &mask, 1);
+  XSync(d, False);
+  return opcode;
+}
+
+// waitModifier reports every press of keycode that is released without
+// another key or a mouse button being pressed meanwhile as a press and a
+// release of the hotkey, until a ClientMessage arrives. It relies on the
+// raw events selected by selectRawEvents, whose XInput opcode is given.
+void waitModifier(uintptr_t hkhandle, Display *d, int keycode, int opcode) {
+  unsigned char held[32] = {0}; // the other keys held, by keycode
+  int buttons = 0;              // the mouse buttons held
+  int down = 0, used = 0;
+  while (1) {
+    XEvent ev;
+    XNextEvent(d, &ev);
+    if (ev.type == ClientMessage)
+      return;
+    XGenericEventCookie *cookie = &ev.xcookie;
+    if (cookie->type != GenericEvent || cookie->extension != opcode ||
+        !XGetEventData(d, cookie))
+      continue;
+    int kc = ((XIRawEvent *)cookie->data)->detail;
+    switch (cookie->evtype) {
+    case XI_RawKeyPress:
+      if (kc == keycode) {
+        if (!down) { // later presses are auto-repeat
+          down = 1;
+          used = buttons > 0;
+          for (int i = 0; i < 32; i++)
+            used |= held[i] != 0;
+        }
+      } else if (kc > 0 && kc < 256) {
+        held[kc / 8] |= 1 << (kc % 8);
+        used = 1;
+      }
+      break;
+    case XI_RawKeyRelease:
+      if (kc == keycode) {
+        if (down && !used) {
+          hotkeyDown(hkhandle);
+          hotkeyUp(hkhandle);
+        }
+        down = 0;
+      } else if (kc > 0 && kc < 256) {
+        held[kc / 8] &= ~(1 << (kc % 8));
+      }
+      break;
+    case XI_RawButtonPress:
+      buttons++;
+      used = 1;
+      break;
+    case XI_RawButtonRelease:
+      if (buttons > 0)
+        buttons--;
+      break;
+    }
+    XFreeEventData(d, cookie);
+  }
+}
+
+// openCapture opens a connection that actively grabs the whole keyboard, so
+// that every key press is reported to it. Closing the connection, also by
+// the death of the process, releases the grab. It returns NULL and stores
+// the XGrabKeyboard status (or -1 if the display cannot be opened) in
+// *status on failure.
+Display *openCapture(int *status) {
+  Display *d = openDisplay();
+  if (d == NULL) {
+    *status = -1;
+    return NULL;
+  }
+  *status = XGrabKeyboard(d, DefaultRootWindow(d), False, GrabModeAsync,
+                          GrabModeAsync, CurrentTime);
+  if (*status != GrabSuccess) {
+    XCloseDisplay(d);
+    return NULL;
+  }
+  Bool supported; // held keys repeat KeyPress only, see waitHotkey
+  XkbSetDetectableAutoRepeat(d, True, &supported);
+  return d;
+}
+
+// nextKey waits up to timeout milliseconds for a key event on a capturing
+// connection. It stores whether the key was pressed, its keysym without
+// the modifiers, the modifier state, and the keysym and the Latin-1 text
+// typed by the key, as translated by XLookupString, and returns 1, or 0 on
+// timeout and -1 if the connection is broken.
+int nextKey(Display *d, int timeout, int *pressed, unsigned long *keysym,
+            unsigned int *state, unsigned long *typed, char *text, int size,
+            int *n) {
+  struct pollfd pfd = {ConnectionNumber(d), POLLIN, 0};
+  while (1) {
+    while (XPending(d) > 0) {
+      XEvent ev;
+      XNextEvent(d, &ev);
+      if (ev.type != KeyPress && ev.type != KeyRelease)
+        continue;
+      KeySym ks;
+      *n = XLookupString(&ev.xkey, text, size, &ks, NULL);
+      *pressed = ev.type == KeyPress;
+      *keysym = XLookupKeysym(&ev.xkey, 0);
+      *state = ev.xkey.state;
+      *typed = ks;
+      return 1;
+    }
+    int r = poll(&pfd, 1, timeout);
+    if (r == 0 || (r < 0 && errno == EINTR))
+      return 0;
+    if (r < 0 || (pfd.revents & (POLLERR | POLLHUP)))
+      return -1;
+  }
+}
+
+// closeCapture releases the keyboard and closes the connection.
+void closeCapture(Display *d) {
+  XUngrabKeyboard(d, CurrentTime);
+  XCloseDisplay(d);
+}
@@ -10,10 +10,12 @@ package hotkey
 
 /*
 #cgo LDFLAGS: -lX11
+#cgo linux LDFLAGS: -ldl
 #cgo openbsd CFLAGS: -I/usr/X11R6/include
 #cgo openbsd LDFLAGS: -L/usr/X11R6/lib -lX11
 
 #include <stdint.h>
+#include <stdlib.h>
 #include <X11/Xlib.h>
 
 int displayTest();
@@ -21,16 +23,30 @@ Display *openDisplay();
 Window createInvisWindow(Display *d);
 void sendCancel(Display *d, Window window);
 void cleanupConnection(Display *d, Window window);
-int grabHotkey(Display *d, unsigned int* mods, int nmods, int key);
+int keycodeByName(Display *d, const char *name);
+int lookupKeysym(Display *d, unsigned long keysym, unsigned int *mods);
+int grabHotkey(Display *d, unsigned int* mods, int nmods, int keycode);
 void waitHotkey(uintptr_t hkhandle, Display *d);
+int loadXI();
+int selectRawEvents(Display *d);
+void waitModifier(uintptr_t hkhandle, Display *d, int keycode, int opcode);
+Display *openCapture(int *status);
+int nextKey(Display *d, int timeout, int *pressed, unsigned long *keysym,
+            unsigned int *state, unsigned long *typed, char *text, int size,
+            int *n);
+void closeCapture(Display *d);
 */
 import "C"
 import (
 	"context"
 	"errors"
+	"fmt"
 	"runtime"
 	"runtime/cgo"
+	"strings"
 	"sync"
+	"time"
+	"unsafe"
 )
 
 const errmsg = `Failed to initialize the X11 display, and the clipboard package
@@ -58,13 +74,12 @@ func init() {
 }
 
 type platformHotkey struct {
-	mu         sync.Mutex
-	registered bool
-	ctx        context.Context
-	cancel     context.CancelFunc
-	canceled   chan struct{}
-	display    *C.Display
-	window     C.Window
+	ctx      context.Context
+	cancel   context.CancelFunc
+	canceled chan struct{}
+	display  *C.Display
+	window   C.Window
+	opcode   C.int // of XInput, for a modifier-only hotkey
 }
 
 // grabMu serializes the grab in register across hotkeys, because the C side
@@ -72,11 +87,74 @@ type platformHotkey struct {
 // handler while probing for a conflict.
 var grabMu sync.Mutex
 
+// register grabs the hotkey on a connection of its own and starts its event
+// loop. The caller must hold hk.mu.
 func (hk *Hotkey) register() error {
-	hk.mu.Lock()
-	defer hk.mu.Unlock()
-	if hk.registered {
-		return errAlreadyRegistered
+	display := C.openDisplay()
+	if display == nil {
+		return errors.New("hotkey: failed to open the X11 display")
+	}
+	window := C.createInvisWindow(display)
+	if hk.modifierOnly() {
+		if err := hk.listenRaw(display); err != nil {
+			C.cleanupConnection(display, window)
+			return err
+		}
+		hk.start(display, window)
+		return nil
+	}
+	if err := hk.grab(display); err != nil {
+		C.cleanupConnection(display, window)
+		return err
+	}
+
+	hk.start(display, window)
+	return nil
+}
+
+// probe grabs hk on a connection of its own and closes the connection
+// right away, which releases the grab.
+func (hk *Hotkey) probe() error {
+	if hk.modifierOnly() {
+		return nil
+	}
+	display := C.openDisplay()
+	if display == nil {
+		return errors.New("hotkey: failed to open the X11 display")
+	}
+	defer C.XCloseDisplay(display)
+	return hk.grab(display)
+}
+
+// xiLoaded loads libXi, which modifier-only hotkeys use, the first time
+// it is called, and reports whether it is installed.
+var xiLoaded = sync.OnceValue(func() bool { return C.loadXI() == 0 })
+
+// listenRaw makes display receive the raw key events of XInput, which are
+// reported regardless of grabs, for the modifier-only hotkey hk. Nothing
+// is grabbed.
+func (hk *Hotkey) listenRaw(display *C.Display) error {
+	if _, err := hk.keycode(display); err != nil {
+		return err
+	}
+	if !xiLoaded() {
+		return fmt.Errorf("%w: libXi is not installed", ErrUnsupported)
+	}
+	opcode := C.selectRawEvents(display)
+	if opcode < 0 {
+		return fmt.Errorf("%w: the X server does not support XInput 2.1", ErrUnsupported)
+	}
+	hk.opcode = opcode
+	return nil
+}
+
+// grab grabs hk on display. The grab is synchronous so a conflict surfaces
+// here as a *ConflictError instead of crashing the program later via Xlib's
+// default error handler.
+func (hk *Hotkey) grab(display *C.Display) error {
+	keycode, err := hk.keycode(display)
+	if err != nil {
+		return err
 	}
 
 	var mod Modifier
@@ -84,45 +162,43 @@ func (hk *Hotkey) register() error {
 		mod = mod | m
 	}
 	// Grab the hotkey once per NumLock/CapsLock state so it fires regardless
-	// of those locks (see lockVariants).
-	variants := lockVariants(mod)
+	// of those locks (see lockVariants). A lock among the modifiers is
+	// dropped, so that Ctrl and Ctrl+Mod2 grab the same keys.
+	variants := lockVariants(mod &^ (x11LockMask | x11Mod2Mask))
 	cmods := make([]C.uint, len(variants))
 	for i, v := range variants {
 		cmods[i] = C.uint(v)
 	}
 
-	display := C.openDisplay()
-	if display == nil {
-		return errors.New("hotkey: failed to open the X11 display")
-	}
-	window := C.createInvisWindow(display)
-
-	// Grab synchronously so a conflict surfaces here as an error instead of
-	// crashing the program later via Xlib's default error handler.
 	grabMu.Lock()
-	rc := C.grabHotkey(display, &cmods[0], C.int(len(cmods)), C.int(hk.key))
+	rc := C.grabHotkey(display, &cmods[0], C.int(len(cmods)), keycode)
 	grabMu.Unlock()
-	if rc != 0 {
-		C.cleanupConnection(display, window)
-		return errRegisterFailed
+	switch rc {
+	case 0:
+		return nil
+	case C.BadAccess:
+		return &ConflictError{Mods: hk.mods, Key: hk.key, Backend: "x11", Code: int(rc), temporary: true}
+	default:
+		return fmt.Errorf("hotkey: failed to grab %v: X error code %d", combination(hk.mods, hk.key), rc)
 	}
+}
+
+// unregister stops the event loop and releases the grab. The caller must
+// hold hk.mu.
+func (hk *Hotkey) unregister() { hk.stop() }
 
+// start runs the event loop of a grab made on display.
+func (hk *Hotkey) start(display *C.Display, window C.Window) {
 	hk.display = display
 	hk.window = window
-	hk.registered = true
 	hk.ctx, hk.cancel = context.WithCancel(context.Background())
 	hk.canceled = make(chan struct{})
-
 	go hk.handle()
-	return nil
 }
 
-func (hk *Hotkey) unregister() error {
-	hk.mu.Lock()
-	defer hk.mu.Unlock()
-	if !hk.registered {
-		return errNotRegistered
-	}
+// stop ends the event loop started by start and closes its connection,
+// which releases the grab.
+func (hk *Hotkey) stop() {
 	hk.cancel()
 	C.sendCancel(hk.display, hk.window)
 	<-hk.canceled
@@ -131,8 +207,6 @@ func (hk *Hotkey) unregister() error {
 	// would destroy the window out from under the in-flight XSendEvent).
 	C.cleanupConnection(hk.display, hk.window)
 	hk.display = nil
-	hk.registered = false
-	return nil
 }
 
 // handle delivers events for an already-registered (and grabbed) hotkey until
@@ -150,11 +224,212 @@ func (hk *Hotkey) handle() {
 			close(hk.canceled)
 			return
 		default:
-			C.waitHotkey(C.uintptr_t(h), hk.display)
+			if hk.modifierOnly() {
+				C.waitModifier(C.uintptr_t(h), hk.display, C.int(hk.physicalKey()), hk.opcode)
+			} else {
+				C.waitHotkey(C.uintptr_t(h), hk.display)
+			}
 		}
 	}
 }
 
+// keycode returns the X keycode to grab for hk on display. A physical key
+// already is a keycode; a keysym is translated through the current keymap.
+// Keys that are not on the keyboard are rejected rather than passed on as
+// keycode 0, which X interprets as AnyKey.
+func (hk *Hotkey) keycode(display *C.Display) (C.int, error) {
+	if hk.physical {
+		key := hk.physicalKey()
+		if key == AnyKey && !hk.modifierOnly() {
+			return C.AnyKey, nil
+		}
+		var min, max C.int
+		C.XDisplayKeycodes(display, &min, &max)
+		if C.int(key) < min || C.int(key) > max {
+			return 0, fmt.Errorf("%w: keycode %d", ErrKeyNotOnKeyboard, key)
+		}
+		return C.int(key), nil
+	}
+
+	keycode := C.int(C.XKeysymToKeycode(display, C.KeySym(hk.key)))
+	if keycode == 0 {
+		name := "NoSymbol"
+		if s := C.XKeysymToString(C.KeySym(hk.key)); s != nil {
+			name = C.GoString(s)
+		}
+		return 0, fmt.Errorf("%w: %s (keysym %#x)", ErrKeyNotOnKeyboard, name, hk.key)
+	}
+	return keycode, nil
+}
+
+// resolveCombo returns the combo that grab registers for mods+key: the
+// keycode of the key, and the modifiers without the locks, which every
+// grab includes in all states anyway. Keysyms on the same key and
+// modifiers that differ only in the locks are thus the same combo.
+func resolveCombo(mods []Modifier, key Key, physical bool) (combo, error) {
+	c := comboOf(mods, key, physical)
+	c.mods &^= x11LockMask | x11Mod2Mask
+	hk := &Hotkey{mods: mods, key: key, physical: physical}
+	if hk.modifierOnly() {
+		return c, nil
+	}
+	display := C.openDisplay()
+	if display == nil {
+		return combo{}, errors.New("hotkey: failed to open the X11 display")
+	}
+	defer C.XCloseDisplay(display)
+	keycode, err := hk.keycode(display)
+	if err != nil {
+		return combo{}, err
+	}
+	c.key, c.physical = Key(keycode), true
+	return c, nil
+}
+
+func physicalKeyByName(name string) (PhysicalKey, error) {
+	// keycodeByName compares only the first XkbKeyNameLength (4) bytes,
+	// and cannot see past a NUL.
+	if name == "" || len(name) > 4 || strings.ContainsRune(name, 0) {
+		return 0, fmt.Errorf("hotkey: invalid XKB key name %q", name)
+	}
+	display := C.openDisplay()
+	if display == nil {
+		return 0, errors.New("hotkey: failed to open the X11 display")
+	}
+	defer C.XCloseDisplay(display)
+
+	cname := C.CString(name)
+	defer C.free(unsafe.Pointer(cname))
+	keycode := C.keycodeByName(display, cname)
+	if keycode == 0 {
+		return 0, fmt.Errorf("hotkey: no key named %q in the X11 keymap", name)
+	}
+	return PhysicalKey(keycode), nil
+}
+
+func newRune(mods []Modifier, r rune) (*Hotkey, error) {
+	display := C.openDisplay()
+	if display == nil {
+		return nil, errors.New("hotkey: failed to open the X11 display")
+	}
+	defer C.XCloseDisplay(display)
+
+	var extra C.uint
+	keycode := C.lookupKeysym(display, C.ulong(runeKeysym(r)), &extra)
+	if keycode == 0 {
+		return nil, errNotTypable(r)
+	}
+	if extra != 0 {
+		mods = append(mods[:len(mods):len(mods)], Modifier(extra))
+	}
+	return NewPhysical(mods, PhysicalKey(keycode)), nil
+}
+
+// runeKeysym returns the keysym of r. Latin-1 characters have legacy
+// keysyms equal to their code point; every other character is mapped to
+// the Unicode keysym range (see /usr/include/X11/keysymdef.h).
+func runeKeysym(r rune) Key {
+	if (r >= 0x20 && r <= 0x7e) || (r >= 0xa0 && r <= 0xff) {
+		return Key(r)
+	}
+	return Key(0x01000000 | r)
+}
+
+const (
+	captureSupported      = true
+	modifierOnlySupported = true
+	anyKeySupported       = true
+	keyEscape             = KeyEscape
+)
+
+// x11Keyboard is a connection that grabs the whole keyboard.
+type x11Keyboard struct{ display *C.Display }
+
+// captureKeyboard grabs the whole keyboard on a connection of its own. The
+// passive grab of a hotkey stays active until its key is released, so the
+// keyboard is often grabbed already when a hotkey fires; captureKeyboard
+// keeps trying until ctx is done.
+func captureKeyboard(ctx context.Context) (keyboard, error) {
+	for {
+		var status C.int
+		if d := C.openCapture(&status); d != nil {
+			return &x11Keyboard{display: d}, nil
+		}
+		switch status {
+		case -1:
+			return nil, errors.New("hotkey: failed to open the X11 display")
+		case C.AlreadyGrabbed, C.GrabFrozen:
+		default:
+			return nil, fmt.Errorf("hotkey: failed to grab the keyboard: status %d", status)
+		}
+
+		t := time.NewTimer(10 * time.Millisecond)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return nil, ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+func (k *x11Keyboard) next(ctx context.Context) (KeyEvent, error) {
+	var (
+		pressed C.int
+		keysym  C.ulong
+		state   C.uint
+		typed   C.ulong
+		text    [32]C.char
+		n       C.int
+	)
+	for {
+		if err := ctx.Err(); err != nil {
+			return KeyEvent{}, err
+		}
+		// Wait in short slices, so that ctx is checked regularly.
+		switch C.nextKey(k.display, 100, &pressed, &keysym, &state, &typed, &text[0], C.int(len(text)), &n) {
+		case 1:
+			kind := EventKeyup
+			if pressed != 0 {
+				kind = EventKeydown
+			}
+			return KeyEvent{
+				Kind: kind,
+				Key:  Key(keysym),
+				Mods: stateModifiers(Modifier(state)),
+				Text: keyText(Key(typed), C.GoBytes(unsafe.Pointer(&text[0]), n)),
+			}, nil
+		case -1:
+			return KeyEvent{}, errors.New("hotkey: lost the X11 connection")
+		}
+	}
+}
+
+func (k *x11Keyboard) close() { C.closeCapture(k.display) }
+
+// isModifierKey reports whether k is a modifier keysym, like the
+// IsModifierKey macro of Xutil.h.
+func isModifierKey(k Key) bool {
+	return (k >= 0xffe1 && k <= 0xffee) || // Shift_L to Hyper_R
+		(k >= 0xfe01 && k <= 0xfe13) || // ISO_Lock to ISO_Level5_Lock
+		k == 0xff7e || k == 0xff7f // Mode_switch, Num_Lock
+}
+
+// stateModifiers splits the modifier state of a key event into modifiers,
+// leaving out CapsLock and NumLock (see lockVariants).
+func stateModifiers(state Modifier) []Modifier {
+	var mods []Modifier
+	for _, m := range []Modifier{ModCtrl, ModShift, Mod1, Mod3, Mod4, Mod5} {
+		if state&m != 0 {
+			mods = append(mods, m)
+		}
+	}
+	return mods
+}
+
+// alternativeModifiers are the modifiers added by Alternatives, see keyRows.
+var alternativeModifiers = []Modifier{ModShift, ModCtrl, Mod1, Mod4}
+
 // X11 lock modifier masks (see /usr/include/X11/X.h).
 const (
 	x11LockMask Modifier = 1 << 1 // CapsLock
@@ -183,13 +458,19 @@ func lockVariants(mod Modifier) []uint32 {
 //export hotkeyDown
 func hotkeyDown(h uintptr) {
 	hk := cgo.Handle(h).Value().(*Hotkey)
-	hk.keydownIn <- Event{}
+	hk.emit(EventKeydown)
 }
 
 //export hotkeyUp
 func hotkeyUp(h uintptr) {
 	hk := cgo.Handle(h).Value().(*Hotkey)
-	hk.keyupIn <- Event{}
+	hk.emit(EventKeyup)
+}
+
+//export hotkeyRepeat
+func hotkeyRepeat(h uintptr) {
+	hk := cgo.Handle(h).Value().(*Hotkey)
+	hk.emit(EventRepeat)
 }
 
 // Modifier represents a modifier.
@@ -42,3 +42,47 @@ func TestLockVariants(t *testing.T) {
 		}
 	}
 }
+
+func TestRuneKeysym(t *testing.T) {
+	for _, tt := range []struct {
+		r    rune
+		want Key
+	}{
+		{'a', KeyA},
+		{'!', 0x21},
+		{'é', 0xe9},       // Latin-1 keysym eacute
+		{'€', 0x010020ac}, // Unicode keysym U20AC
+		{'ж', 0x01000436}, // Unicode keysym U0436
+		{'\u0080', 0x01000080},
+	} {
+		if got := runeKeysym(tt.r); got != tt.want {
+			t.Errorf("runeKeysym(%q) = %#x, want %#x", tt.r, got, tt.want)
+		}
+	}
+}
+
+// TestKeyText verifies that the text of a captured key is decoded from
+// its keysym beyond Latin-1, and that the control characters typed with
+// Ctrl are dropped.
+func TestKeyText(t *testing.T) {
+	for _, tt := range []struct {
+		keysym Key
+		in     []byte
+		want   string
+	}{
+		{0x61, []byte("a"), "a"},
+		{0xe9, []byte{0xe9}, "é"},
+		{0x61, []byte{0x01}, ""},   // Ctrl+A
+		{0x06c1, nil, "а"},         // Cyrillic_a
+		{0x07e1, nil, "α"},         // Greek_alpha
+		{0x20ac, nil, "€"},         // EuroSign
+		{0x01004e2d, nil, "中"},     // Unicode keysym U4E2D
+		{0xffb7, nil, "7"},         // KP_7
+		{0xff0d, []byte{0x0d}, ""}, // Return
+		{0xffbe, nil, ""},          // F1
+	} {
+		if got := keyText(tt.keysym, tt.in); got != tt.want {
+			t.Errorf("keyText(%#x, %q) = %q, want %q", tt.keysym, tt.in, got, tt.want)
+		}
+	}
+}
@@ -9,8 +9,17 @@
 package hotkey_test
 
 import (
+	"bufio"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"syscall"
 	"testing"
 	"time"
 
@@ -41,17 +50,414 @@ func TestKeycodesAreDistinct(t *testing.T) {
 // grabbed by another X client returns an error instead of crashing the
 // process via Xlib's default BadAccess handler (issue #11).
 func TestRegisterConflict(t *testing.T) {
-	hk1 := hotkey.New([]hotkey.Modifier{hotkey.ModCtrl, hotkey.ModShift}, hotkey.KeyF8)
-	if err := hk1.Register(); err != nil {
+	release, err := grabElsewhere(t, []hotkey.Modifier{hotkey.ModCtrl, hotkey.ModShift}, hotkey.KeyF8)
+	if err != nil {
 		t.Fatalf("first registration failed: %v", err)
 	}
-	defer hk1.Unregister()
+	defer release()
 
 	hk2 := hotkey.New([]hotkey.Modifier{hotkey.ModCtrl, hotkey.ModShift}, hotkey.KeyF8)
-	if err := hk2.Register(); err == nil {
+	err = hk2.Register()
+	if err == nil {
 		hk2.Unregister()
 		t.Fatal("registering an already-grabbed hotkey should return an error, got nil")
 	}
+	if !errors.Is(err, hotkey.ErrRegisterFailed) {
+		t.Errorf("got %v, want ErrRegisterFailed", err)
+	}
+	var cerr *hotkey.ConflictError
+	if !errors.As(err, &cerr) {
+		t.Fatalf("got %T, want *hotkey.ConflictError", err)
+	}
+	const badAccess = 10 // see /usr/include/X11/X.h
+	if cerr.Backend != "x11" || cerr.Code != badAccess || !cerr.Temporary() || cerr.Key != hotkey.KeyF8 {
+		t.Errorf("unexpected conflict details: %+v", cerr)
+	}
+}
+
+// TestShare verifies that hotkeys of the same process with the same
+// combination share one grab, which is released with the last of them.
+func TestShare(t *testing.T) {
+	mods := []hotkey.Modifier{hotkey.ModCtrl, hotkey.ModShift}
+	hk1, hk2 := hotkey.New(mods, hotkey.KeyF8), hotkey.New(mods, hotkey.KeyF8)
+	if err := hk1.Register(); err != nil {
+		t.Fatalf("first registration failed: %v", err)
+	}
+	if err := hk2.Register(); err != nil {
+		t.Fatalf("registering a combination of this process failed: %v", err)
+	}
+
+	hk1.Unregister()
+	if release, err := grabElsewhere(t, mods, hotkey.KeyF8); err == nil {
+		release()
+		t.Fatal("the grab was released while a hotkey still uses it")
+	}
+	hk2.Unregister()
+	release, err := grabElsewhere(t, mods, hotkey.KeyF8)
+	if err != nil {
+		t.Fatalf("the grab was not released with the last hotkey: %v", err)
+	}
+	release()
+}
+
+// TestShareResolved verifies that hotkeys which grab the same keys share
+// the grab even though they are spelled differently: by keysym, by rune
+// or by keycode, and with or without a lock modifier.
+func TestShareResolved(t *testing.T) {
+	mods := []hotkey.Modifier{hotkey.ModCtrl, hotkey.ModShift}
+	code, err := hotkey.PhysicalKeyByName("AC01")
+	if err != nil {
+		t.Fatalf("resolving AC01 failed: %v", err)
+	}
+	byRune, err := hotkey.NewRune(mods, 'a')
+	if err != nil {
+		t.Fatalf("NewRune failed: %v", err)
+	}
+	hks := []*hotkey.Hotkey{
+		hotkey.New(mods, hotkey.KeyA),
+		byRune,
+		hotkey.NewPhysical(mods, code),
+		hotkey.New(append(mods, hotkey.Mod2), hotkey.KeyA),
+	}
+	for i, hk := range hks {
+		if err := hk.Register(); err != nil {
+			t.Fatalf("registering %v (#%d) failed: %v", hk, i, err)
+		}
+		defer hk.Unregister()
+	}
+}
+
+// TestPhysicalKey verifies that XKB key names resolve through the keymap of
+// the X server and that a hotkey can be grabbed by its physical key.
+func TestPhysicalKey(t *testing.T) {
+	for _, name := range []string{"NOPE", "", "TLDEX", "TL\x00E"} {
+		if _, err := hotkey.PhysicalKeyByName(name); err == nil {
+			t.Fatalf("resolving the key name %q should return an error, got nil", name)
+		}
+	}
+	code, err := hotkey.PhysicalKeyByName("AE01")
+	if err != nil {
+		t.Fatalf("failed to resolve AE01: %v", err)
+	}
+
+	hk := hotkey.NewPhysical([]hotkey.Modifier{hotkey.ModCtrl, hotkey.ModShift}, code)
+	if err := hk.Register(); err != nil {
+		t.Fatalf("failed to register physical hotkey: %v", err)
+	}
+	if err := hk.Unregister(); err != nil {
+		t.Fatalf("failed to unregister physical hotkey: %v", err)
+	}
+}
+
+// TestNewRune verifies that a character is resolved to a key of the current
+// keymap and that characters missing from the keymap are rejected.
+func TestNewRune(t *testing.T) {
+	hk, err := hotkey.NewRune([]hotkey.Modifier{hotkey.ModCtrl, hotkey.ModShift}, 'q')
+	if err != nil {
+		t.Fatalf("failed to resolve 'q': %v", err)
+	}
+	if err := hk.Register(); err != nil {
+		t.Fatalf("failed to register rune hotkey: %v", err)
+	}
+	hk.Unregister()
+
+	if _, err := hotkey.NewRune(nil, '\U0001F600'); err == nil {
+		t.Fatal("resolving a character missing from the keymap should return an error, got nil")
+	}
+}
+
+// TestRegisterKeyNotOnKeyboard verifies that keys missing from the keymap are
+// rejected instead of being grabbed as keycode 0, which X interprets as
+// AnyKey and which would take every key with those modifiers.
+func TestRegisterKeyNotOnKeyboard(t *testing.T) {
+	mods := []hotkey.Modifier{hotkey.ModCtrl, hotkey.ModShift}
+	for _, hk := range []*hotkey.Hotkey{
+		hotkey.New(mods, hotkey.Key(0)),          // NoSymbol
+		hotkey.New(mods, hotkey.Key(0x0100263a)), // U263A, not in any keymap
+		hotkey.NewPhysical(mods, 3),              // below the minimum keycode
+	} {
+		err := hk.Register()
+		if err == nil {
+			hk.Unregister()
+			t.Errorf("registering %v should fail, got nil", hk)
+			continue
+		}
+		if !errors.Is(err, hotkey.ErrKeyNotOnKeyboard) {
+			t.Errorf("registering %v: got %v, want ErrKeyNotOnKeyboard", hk, err)
+		}
+	}
+}
+
+// TestAvailable verifies that the availability probe reports a combination
+// taken by another client, and that probing does not hold the grab.
+func TestAvailable(t *testing.T) {
+	mods := []hotkey.Modifier{hotkey.ModCtrl, hotkey.ModShift}
+	if ok, err := hotkey.Available(mods, hotkey.KeyF9); !ok || err != nil {
+		t.Fatalf("Available() = %v, %v, want true, nil", ok, err)
+	}
+
+	hk := hotkey.New(mods, hotkey.KeyF9)
+	if err := hk.Register(); err != nil {
+		t.Fatalf("failed to register hotkey after probing it: %v", err)
+	}
+	if ok, err := hotkey.Available(mods, hotkey.KeyF9); !ok || err != nil {
+		t.Errorf("Available() = %v, %v for a combination of this process, want true, nil", ok, err)
+	}
+	hk.Unregister()
+
+	release, err := grabElsewhere(t, mods, hotkey.KeyF9)
+	if err != nil {
+		t.Fatalf("failed to register hotkey after probing it: %v", err)
+	}
+	defer release()
+	if ok, err := hotkey.Available(mods, hotkey.KeyF9); ok || err != nil {
+		t.Errorf("Available() = %v, %v for a taken combination, want false, nil", ok, err)
+	}
+	alts, err := hotkey.Alternatives(mods, hotkey.KeyF9, 2)
+	if err != nil || len(alts) != 2 {
+		t.Errorf("Alternatives() = %v, %v, want two combinations", alts, err)
+	}
+}
+
+// TestRegisterEventually verifies that a combination held by another client
+// is acquired once that client releases it.
+func TestRegisterEventually(t *testing.T) {
+	mods := []hotkey.Modifier{hotkey.ModCtrl, hotkey.ModShift}
+	release, err := grabElsewhere(t, mods, hotkey.KeyF10)
+	if err != nil {
+		t.Fatalf("first registration failed: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	hk2 := hotkey.New(mods, hotkey.KeyF10)
+	acquired := hk2.RegisterEventually(ctx)
+	select {
+	case err := <-acquired:
+		t.Fatalf("acquired a combination that is still held: %v", err)
+	case <-time.After(300 * time.Millisecond):
+	}
+
+	release()
+	if err := <-acquired; err != nil {
+		t.Fatalf("failed to acquire the released combination: %v", err)
+	}
+	hk2.Unregister()
+}
+
+// TestRebind verifies that Rebind keeps the channels, releases the old
+// combination on success and keeps it when the new one is taken.
+func TestRebind(t *testing.T) {
+	mods := []hotkey.Modifier{hotkey.ModCtrl, hotkey.ModShift}
+	hk := hotkey.New(mods, hotkey.KeyF11)
+	if err := hk.Register(); err != nil {
+		t.Fatalf("failed to register hotkey: %v", err)
+	}
+	defer hk.Unregister()
+	keydown := hk.Keydown()
+
+	if err := hk.Rebind(mods, hotkey.KeyF12); err != nil {
+		t.Fatalf("failed to rebind hotkey: %v", err)
+	}
+	if hk.Keydown() != keydown {
+		t.Error("Rebind replaced the Keydown channel")
+	}
+	if ok, _ := hotkey.Available(mods, hotkey.KeyF11); !ok {
+		t.Error("the old combination is still grabbed after Rebind")
+	}
+
+	release, err := grabElsewhere(t, mods, hotkey.KeyF7)
+	if err != nil {
+		t.Fatalf("failed to register hotkey: %v", err)
+	}
+	defer release()
+	if err := hk.Rebind(mods, hotkey.KeyF7); !errors.Is(err, hotkey.ErrRegisterFailed) {
+		t.Fatalf("rebinding to a taken combination: got %v, want ErrRegisterFailed", err)
+	}
+	if release, err := grabElsewhere(t, mods, hotkey.KeyF12); err == nil {
+		release()
+		t.Error("a failed Rebind released the old combination")
+	}
+}
+
+// TestRegisterAll verifies that a conflict rolls back the whole batch and is
+// reported together with every other failure.
+func TestRegisterAll(t *testing.T) {
+	mods := []hotkey.Modifier{hotkey.ModCtrl, hotkey.ModShift}
+	release, err := grabElsewhere(t, mods, hotkey.KeyF6)
+	if err != nil {
+		t.Fatalf("failed to register hotkey: %v", err)
+	}
+	defer release()
+
+	hks := []*hotkey.Hotkey{
+		hotkey.New(mods, hotkey.KeyF1),
+		hotkey.New(mods, hotkey.KeyF6),
+		hotkey.New(mods, hotkey.KeyF2),
+		hotkey.New(mods, hotkey.Key(0)),
+	}
+	err = hotkey.RegisterAll(hks...)
+	var rerr *hotkey.RegisterError
+	if !errors.As(err, &rerr) || len(rerr.Failed) != 2 {
+		t.Fatalf("RegisterAll() = %v, want a *RegisterError listing two hotkeys", err)
+	}
+	if !errors.Is(err, hotkey.ErrRegisterFailed) || !errors.Is(err, hotkey.ErrKeyNotOnKeyboard) {
+		t.Errorf("RegisterAll() = %v, want both failures listed", err)
+	}
+	for _, key := range []hotkey.Key{hotkey.KeyF1, hotkey.KeyF2} {
+		if ok, _ := hotkey.Available(mods, key); !ok {
+			t.Errorf("RegisterAll did not roll back %v", key)
+		}
+	}
+
+	ok, err := hotkey.RegisterSome(hks...)
+	if len(ok) != 2 || err == nil {
+		t.Errorf("RegisterSome() = %v, %v, want two registered hotkeys and an error", ok, err)
+	}
+	for _, hk := range ok {
+		hk.Unregister()
+	}
+}
+
+// TestManager verifies that a Manager lists, disables and enables its
+// hotkeys and reports them in its JSON snapshot.
+func TestManager(t *testing.T) {
+	m := hotkey.NewManager()
+	defer m.Close()
+
+	mods := []hotkey.Modifier{hotkey.ModCtrl, hotkey.ModShift}
+	if err := m.Add("open", hotkey.New(mods, hotkey.KeyF7)); err != nil {
+		t.Fatalf("failed to add hotkey: %v", err)
+	}
+	if err := m.Add("save", hotkey.New(mods, hotkey.KeyF8)); err != nil {
+		t.Fatalf("failed to add hotkey: %v", err)
+	}
+	if err := m.Add("save", hotkey.New(mods, hotkey.KeyF9)); err == nil {
+		t.Errorf("Add() accepted a duplicated id")
+	}
+	if got := m.List(); len(got) != 2 || got[0] != "open" || got[1] != "save" {
+		t.Errorf("List() = %v, want [open save]", got)
+	}
+
+	if err := m.Disable("open"); err != nil {
+		t.Fatalf("failed to disable hotkey: %v", err)
+	}
+	if ok, _ := hotkey.Available(mods, hotkey.KeyF7); !ok {
+		t.Errorf("Disable did not release the combination")
+	}
+	b, err := json.Marshal(m)
+	if err != nil {
+		t.Fatalf("failed to marshal manager: %v", err)
+	}
+	want := fmt.Sprintf(`[{"id":"open","hotkey":%q,"enabled":false},{"id":"save","hotkey":%q,"enabled":true}]`,
+		hotkey.New(mods, hotkey.KeyF7), hotkey.New(mods, hotkey.KeyF8))
+	if string(b) != want {
+		t.Errorf("json.Marshal() = %s, want %s", b, want)
+	}
+	if err := m.Enable("open"); err != nil {
+		t.Errorf("failed to enable hotkey: %v", err)
+	}
+
+	if err := m.Remove("save"); err != nil {
+		t.Fatalf("failed to remove hotkey: %v", err)
+	}
+	if _, ok := m.Lookup("save"); ok {
+		t.Errorf("Lookup() found a removed hotkey")
+	}
+	if err := m.Disable("save"); !errors.Is(err, hotkey.ErrUnknownID) {
+		t.Errorf("Disable() = %v, want %v", err, hotkey.ErrUnknownID)
+	}
+
+	m.Close()
+	if _, ok := <-m.Events(); ok {
+		t.Errorf("Events() is not closed after Close")
+	}
+}
+
+// TestNotify verifies that Notify grabs the combinations all or none and
+// that Stop releases them.
+func TestNotify(t *testing.T) {
+	mods := []hotkey.Modifier{hotkey.ModCtrl, hotkey.ModShift}
+	c := make(chan hotkey.Triggered, 1)
+	if err := hotkey.Notify(c, hotkey.Combination{Mods: mods, Key: hotkey.KeyF10}); err != nil {
+		t.Fatalf("Notify() = %v", err)
+	}
+	if release, err := grabElsewhere(t, mods, hotkey.KeyF10); err == nil {
+		release()
+		t.Errorf("Notify did not grab the combination")
+	}
+
+	release, err := grabElsewhere(t, mods, hotkey.KeyF12)
+	if err != nil {
+		t.Fatalf("failed to register hotkey: %v", err)
+	}
+	defer release()
+	d := make(chan hotkey.Triggered, 1)
+	err = hotkey.Notify(d,
+		hotkey.Combination{Mods: mods, Key: hotkey.KeyF11},
+		hotkey.Combination{Mods: mods, Key: hotkey.KeyF12},
+	)
+	if !errors.Is(err, hotkey.ErrRegisterFailed) {
+		t.Errorf("Notify() = %v, want %v", err, hotkey.ErrRegisterFailed)
+	}
+	if ok, _ := hotkey.Available(mods, hotkey.KeyF11); !ok {
+		t.Errorf("a failed Notify kept a combination grabbed")
+	}
+
+	hotkey.Stop(c)
+	if ok, _ := hotkey.Available(mods, hotkey.KeyF10); !ok {
+		t.Errorf("Stop did not release the combination")
+	}
+}
+
+// TestCapture verifies that a capture ends with its context and releases
+// the keyboard, so that it can be captured again.
+func TestCapture(t *testing.T) {
+	for range 2 {
+		ctx, cancel := context.WithCancel(context.Background())
+		keys, err := hotkey.Capture(ctx)
+		if err != nil {
+			cancel()
+			t.Fatalf("failed to capture the keyboard: %v", err)
+		}
+		cancel()
+		for range keys {
+		}
+	}
+}
+
+// TestModifierOnly verifies that a modifier-only hotkey does not grab
+// anything, so that other clients can still grab combinations with its
+// modifier, and that the left and right keys of a modifier are distinct.
+func TestModifierOnly(t *testing.T) {
+	left, err := hotkey.PhysicalKeyByName("LWIN")
+	if err != nil {
+		t.Fatalf("failed to resolve LWIN: %v", err)
+	}
+	right, err := hotkey.PhysicalKeyByName("RWIN")
+	if err != nil {
+		t.Fatalf("failed to resolve RWIN: %v", err)
+	}
+	hk := hotkey.NewModifierOnly(left)
+	defer hk.Close()
+	err = hk.Register()
+	if errors.Is(err, hotkey.ErrUnsupported) {
+		t.Skipf("modifier-only hotkeys are not supported: %v", err)
+	}
+	if err != nil {
+		t.Fatalf("failed to register: %v", err)
+	}
+	if hk.String() == hotkey.NewModifierOnly(right).String() {
+		t.Errorf("LWIN and RWIN are the same hotkey %v", hk)
+	}
+	release, err := grabElsewhere(t, []hotkey.Modifier{hotkey.Mod4}, hotkey.KeyX)
+	if err != nil {
+		t.Fatalf("Super+X cannot be grabbed while Super alone is registered: %v", err)
+	}
+	release()
+	if err := hk.Unregister(); err != nil {
+		t.Fatalf("failed to unregister: %v", err)
+	}
 }
 
 // TestHotkey should always run success.
@@ -70,11 +476,8 @@ func TestHotkey(t *testing.T) {
 	// Release the grab on return; otherwise it would leak and conflict with
 	// later tests that register the same combination.
 	defer hk.Unregister()
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case <-hk.Keydown():
+	for e := range hk.All(ctx) {
+		if e.Kind == hotkey.EventKeydown {
 			fmt.Println("triggered")
 		}
 	}
@@ -102,3 +505,168 @@ func TestHotkey_Unregister(t *testing.T) {
 		}
 	}
 }
+
+// TestHotkey_Lifecycle verifies that the channels are stable across
+// Unregister and Register, that Done releases a waiting reader, and that
+// concurrent use is race-free (run with -race).
+func TestHotkey_Lifecycle(t *testing.T) {
+	hk := hotkey.New([]hotkey.Modifier{hotkey.ModCtrl, hotkey.ModShift}, hotkey.KeyF5)
+	keydown, keyup := hk.Keydown(), hk.Keyup()
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				case <-hk.Keydown():
+				case <-hk.Done():
+				default:
+					_ = hk.String()
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < 10; i++ {
+		if err := hk.Register(); err != nil {
+			t.Fatalf("failed to register hotkey: %v", err)
+		}
+		done := hk.Done()
+		released := make(chan struct{})
+		go func() {
+			select {
+			case <-hk.Keydown():
+			case <-done:
+			}
+			close(released)
+		}()
+		if err := hk.Unregister(); err != nil {
+			t.Fatalf("failed to unregister hotkey: %v", err)
+		}
+		select {
+		case <-released:
+		case <-time.After(time.Second):
+			t.Fatal("Done was not closed by Unregister")
+		}
+	}
+	close(stop)
+	wg.Wait()
+
+	if hk.Keydown() != keydown || hk.Keyup() != keyup {
+		t.Error("the event channels changed during the lifetime of the hotkey")
+	}
+}
+
+// TestHotkey_Close verifies that Close releases the grab, ends readers of the
+// event channels and makes the hotkey unusable.
+func TestHotkey_Close(t *testing.T) {
+	mods := []hotkey.Modifier{hotkey.ModCtrl, hotkey.ModShift}
+	hk := hotkey.New(mods, hotkey.KeyF4)
+	if err := hk.Register(); err != nil {
+		t.Fatalf("failed to register hotkey: %v", err)
+	}
+	if err := hk.Close(); err != nil {
+		t.Fatalf("failed to close hotkey: %v", err)
+	}
+	if err := hk.Close(); err != nil {
+		t.Errorf("closing twice: %v", err)
+	}
+	if _, ok := <-hk.Keydown(); ok {
+		t.Error("Keydown is still open after Close")
+	}
+	if ok, _ := hotkey.Available(mods, hotkey.KeyF4); !ok {
+		t.Error("Close did not release the grab")
+	}
+	if err := hk.Register(); !errors.Is(err, hotkey.ErrClosed) {
+		t.Errorf("registering a closed hotkey: got %v, want ErrClosed", err)
+	}
+}
+
+// TestSignalContext verifies that a signal unregisters every hotkey of the
+// process before the returned context is done.
+func TestSignalContext(t *testing.T) {
+	mods := []hotkey.Modifier{hotkey.ModCtrl, hotkey.ModShift}
+	hks := []*hotkey.Hotkey{hotkey.New(mods, hotkey.KeyF2), hotkey.New(mods, hotkey.KeyF3)}
+	if err := hotkey.RegisterAll(hks...); err != nil {
+		t.Fatalf("failed to register hotkeys: %v", err)
+	}
+
+	ctx, stop := hotkey.SignalContext(context.Background(), syscall.SIGUSR1)
+	defer stop()
+	syscall.Kill(os.Getpid(), syscall.SIGUSR1)
+	select {
+	case <-ctx.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("the context is not done after the signal")
+	}
+	for _, hk := range hks {
+		if err := hk.Unregister(); !errors.Is(err, hotkey.ErrNotRegistered) {
+			t.Errorf("%v is still registered after the signal", hk)
+		}
+	}
+}
+
+// grabElsewhere registers mods+key in a child process, which is another X
+// client, and returns a function that ends the child and so releases the
+// combination. It returns the registration error of the child, if any.
+func grabElsewhere(t *testing.T, mods []hotkey.Modifier, key hotkey.Key) (func(), error) {
+	t.Helper()
+	var mask hotkey.Modifier
+	for _, m := range mods {
+		mask |= m
+	}
+	cmd := exec.Command(os.Args[0], "-test.run=^TestHelperGrab$")
+	cmd.Env = append(os.Environ(), fmt.Sprintf("HOTKEY_TEST_GRAB=%d:%d", mask, key))
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		t.Fatalf("failed to start helper: %v", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatalf("failed to start helper: %v", err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("failed to start helper: %v", err)
+	}
+	release := func() {
+		stdin.Close()
+		cmd.Wait()
+	}
+	line, err := bufio.NewReader(stdout).ReadString('\n')
+	if err != nil {
+		release()
+		t.Fatalf("failed to read from helper: %v", err)
+	}
+	if line = strings.TrimSpace(line); line != "ready" {
+		release()
+		return func() {}, errors.New(line)
+	}
+	return release, nil
+}
+
+// TestHelperGrab is not a real test. It is run by grabElsewhere in a child
+// process, holds the combination given in its environment until its
+// standard input is closed.
+func TestHelperGrab(t *testing.T) {
+	spec := os.Getenv("HOTKEY_TEST_GRAB")
+	if spec == "" {
+		t.Skip("only run by grabElsewhere")
+	}
+	var mods, key uint32
+	if _, err := fmt.Sscanf(spec, "%d:%d", &mods, &key); err != nil {
+		t.Fatalf("invalid HOTKEY_TEST_GRAB %q: %v", spec, err)
+	}
+	hk := hotkey.New([]hotkey.Modifier{hotkey.Modifier(mods)}, hotkey.Key(key))
+	if err := hk.Register(); err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer hk.Unregister()
+	fmt.Println("ready")
+	io.Copy(io.Discard, os.Stdin)
+}
@@ -22,6 +22,8 @@ var (
 	peekMessage      = user32.NewProc("PeekMessageA")
 	sendMessage      = user32.NewProc("SendMessageW")
 	getAsyncKeyState = user32.NewProc("GetAsyncKeyState")
+	mapVirtualKey    = user32.NewProc("MapVirtualKeyW")
+	vkKeyScan        = user32.NewProc("VkKeyScanW")
 	quitMessage      = user32.NewProc("PostQuitMessage")
 )
 
@@ -120,3 +122,33 @@ func GetAsyncKeyState(keycode int) uintptr {
 	ret, _, _ := getAsyncKeyState.Call(uintptr(keycode))
 	return ret
 }
+
+// MapVirtualKey translation types.
+// https://docs.microsoft.com/en-us/windows/win32/api/winuser/nf-winuser-mapvirtualkeyw
+const (
+	MAPVK_VK_TO_VSC    = 0
+	MAPVK_VSC_TO_VK    = 1
+	MAPVK_VK_TO_CHAR   = 2
+	MAPVK_VSC_TO_VK_EX = 3
+)
+
+// MapVirtualKey translates a virtual-key code into a scan code or
+// character value, or translates a scan code into a virtual-key code.
+// It returns 0 if there is no translation.
+//
+// https://docs.microsoft.com/en-us/windows/win32/api/winuser/nf-winuser-mapvirtualkeyw
+func MapVirtualKey(code, mapType uint32) uint32 {
+	ret, _, _ := mapVirtualKey.Call(uintptr(code), uintptr(mapType))
+	return uint32(ret)
+}
+
+// VkKeyScan translates a character to the corresponding virtual-key code
+// and shift state for the current keyboard. The low byte of the result is
+// the virtual-key code and the high byte the shift state (1: Shift, 2: Ctrl,
+// 4: Alt). Both bytes are -1 if the character cannot be translated.
+//
+// https://docs.microsoft.com/en-us/windows/win32/api/winuser/nf-winuser-vkkeyscanw
+func VkKeyScan(ch uint16) int16 {
+	ret, _, _ := vkKeyScan.Call(uintptr(ch))
+	return int16(ret)
+}
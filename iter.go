@@ -0,0 +1,59 @@
+// Copyright 2026 The golang.design Initiative Authors.
+// All rights reserved. Use of this source code is governed
+// by a MIT license that can be found in the LICENSE file.
+
+package hotkey
+
+import (
+	"context"
+	"iter"
+)
+
+// All returns an iterator over the events of the hotkey, both presses and
+// releases, as received by a subscription of its own (see Subscribe). The
+// iteration ends when ctx is done, or when the hotkey is unregistered,
+// after the events that happened before. It watches Done, so it ends right
+// away on a hotkey that was unregistered and not registered again, but
+// waits for ctx on a hotkey that was never registered.
+func (hk *Hotkey) All(ctx context.Context) iter.Seq[Event] {
+	return func(yield func(Event) bool) {
+		done := hk.Done()
+		events, unsubscribe := hk.Subscribe(Buffer{})
+		defer unsubscribe()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-done:
+				unsubscribe() // closes events once they are drained
+				for e := range events {
+					if !yield(e) {
+						return
+					}
+				}
+				return
+			case e, ok := <-events:
+				if !ok || !yield(e) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// All returns an iterator over the IDs and events received on Events. The
+// iteration ends when ctx is done or the manager is closed.
+func (m *Manager) All(ctx context.Context) iter.Seq2[string, Event] {
+	return func(yield func(string, Event) bool) {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case e, ok := <-m.out:
+				if !ok || !yield(e.ID, e.Event) {
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright 2026 The golang.design Initiative Authors.
+// All rights reserved. Use of this source code is governed
+// by a MIT license that can be found in the LICENSE file.
+
+//go:build linux || openbsd
+
+package hotkey
+
+//go:generate go run gen_keysym.go
+
+import "unicode"
+
+// keyText returns the text typed by a key with the given keysym. b is the
+// text of the key as looked up by XLookupString, which is limited to
+// Latin-1 but tells when Ctrl turned the key into a control character;
+// such keys type no text.
+func keyText(keysym Key, b []byte) string {
+	if len(b) > 0 && latin1Text(b) == "" {
+		return ""
+	}
+	if r, ok := keysymRune(keysym); ok {
+		return string(r)
+	}
+	return latin1Text(b)
+}
+
+// keysymRune returns the character typed by a keysym, if any.
+func keysymRune(k Key) (rune, bool) {
+	switch {
+	case (k >= 0x20 && k <= 0x7e) || (k >= 0xa0 && k <= 0xff): // Latin-1
+		return rune(k), true
+	case k >= 0x01000100 && k <= 0x0110ffff: // Unicode keysyms
+		if r := rune(k - 0x01000000); !unicode.IsControl(r) {
+			return r, true
+		}
+		return 0, false
+	case k >= 0xffb0 && k <= 0xffb9: // KP_0 to KP_9
+		return rune('0' + k - 0xffb0), true
+	}
+	if r, ok := keypadRunes[k]; ok {
+		return r, true
+	}
+	r, ok := keysymRunes[k]
+	return r, ok
+}
+
+// latin1Text converts the Latin-1 text of XLookupString, leaving out
+// control characters such as the ones typed with Ctrl.
+func latin1Text(b []byte) string {
+	var rs []rune
+	for _, c := range b {
+		if r := rune(c); !unicode.IsControl(r) {
+			rs = append(rs, r)
+		}
+	}
+	return string(rs)
+}
+
+// keypadRunes maps the keypad keysyms other than the digits.
+var keypadRunes = map[Key]rune{
+	0xff80: ' ', // KP_Space
+	0xffaa: '*', // KP_Multiply
+	0xffab: '+', // KP_Add
+	0xffac: ',', // KP_Separator
+	0xffad: '-', // KP_Subtract
+	0xffae: '.', // KP_Decimal
+	0xffaf: '/', // KP_Divide
+	0xffbd: '=', // KP_Equal
+}
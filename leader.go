@@ -0,0 +1,216 @@
+// Copyright 2026 The golang.design Initiative Authors.
+// All rights reserved. Use of this source code is governed
+// by a MIT license that can be found in the LICENSE file.
+
+package hotkey
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// LeaderEventKind tells what happened to a Leader.
+type LeaderEventKind uint8
+
+// The kinds of leader events.
+const (
+	LeaderActive   LeaderEventKind = iota + 1 // the keyboard is captured, waiting for the next key
+	LeaderKey                                 // the next key was pressed
+	LeaderCanceled                            // no key was captured
+)
+
+// String returns the name of the leader event kind.
+func (k LeaderEventKind) String() string {
+	switch k {
+	case LeaderActive:
+		return "active"
+	case LeaderKey:
+		return "key"
+	case LeaderCanceled:
+		return "canceled"
+	}
+	return fmt.Sprintf("LeaderEventKind(%d)", uint8(k))
+}
+
+// LeaderEvent is an event of a Leader.
+type LeaderEvent struct {
+	Kind  LeaderEventKind
+	Press KeyEvent // the key pressed after the leader, for LeaderKey
+	Err   error    // why no key was captured, nil if Escape was pressed
+}
+
+// Leader is a prefix hotkey, like the prefix of tmux: once it is pressed,
+// the whole keyboard is captured until the next key is pressed, so that
+// any key can follow it without being registered. The next key is reported
+// unless it is Escape, and the keyboard is released again after it or
+// after the timeout, in which case the event wraps
+// context.DeadlineExceeded.
+//
+// Capturing the keyboard is only supported on X11; elsewhere Register
+// returns ErrUnsupported.
+type Leader struct {
+	hk      *Hotkey
+	timeout time.Duration
+	capture func(ctx context.Context) (keyboard, error)
+
+	mu      sync.Mutex
+	remove  func() // removes the listener of hk, while registered
+	cancel  context.CancelFunc
+	stopped chan struct{}
+	closed  bool
+
+	in  chan<- LeaderEvent
+	out <-chan LeaderEvent
+}
+
+// NewLeader creates a leader with the given combination. timeout is how
+// long it waits for the next key.
+func NewLeader(mods []Modifier, key Key, timeout time.Duration) *Leader {
+	in, out := newEventChan[LeaderEvent]()
+	return &Leader{
+		hk:      newInternal(mods, key, false),
+		timeout: timeout,
+		capture: captureKeyboard,
+		in:      in,
+		out:     out,
+	}
+}
+
+// Register registers the leader combination.
+func (l *Leader) Register() error {
+	if !captureSupported {
+		return ErrUnsupported
+	}
+	return l.register()
+}
+
+func (l *Leader) register() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return ErrClosed
+	}
+	if l.remove != nil {
+		return ErrAlreadyRegistered
+	}
+
+	in, presses := newEventChan[struct{}]()
+	remove := l.hk.listen(func(e Event) {
+		if e.Kind == EventKeydown {
+			in <- struct{}{}
+		}
+	})
+	if err := l.hk.Register(); err != nil {
+		remove()
+		close(in)
+		return err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	l.remove = func() {
+		remove()
+		close(in)
+	}
+	l.cancel, l.stopped = cancel, make(chan struct{})
+	go l.run(ctx, presses)
+	return nil
+}
+
+// Unregister unregisters the leader combination and releases the keyboard
+// if it is captured.
+func (l *Leader) Unregister() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.remove == nil {
+		return ErrNotRegistered
+	}
+	l.release()
+	return nil
+}
+
+// Close unregisters the leader if it is registered and closes its Events
+// channel. Close is idempotent and always returns nil.
+func (l *Leader) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return nil
+	}
+	if l.remove != nil {
+		l.release()
+	}
+	l.hk.Close()
+	l.closed = true
+	close(l.in)
+	return nil
+}
+
+// release stops the leader. The caller must hold l.mu.
+func (l *Leader) release() {
+	l.hk.Unregister()
+	l.cancel()
+	<-l.stopped
+	l.remove()
+	l.remove = nil
+}
+
+// Events returns the channel that receives the events of the leader. It is
+// closed by Close.
+func (l *Leader) Events() <-chan LeaderEvent { return l.out }
+
+// String returns a string representation of the leader combination.
+func (l *Leader) String() string { return l.hk.String() }
+
+// run captures the next key after every press of the leader, until ctx is
+// canceled.
+func (l *Leader) run(ctx context.Context, presses <-chan struct{}) {
+	defer close(l.stopped)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-presses:
+		}
+
+		e := l.next(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		// Presses queued while the keyboard was captured, such as
+		// auto-repeats of the leader, must not capture it again.
+		for drained := false; !drained; {
+			select {
+			case <-presses:
+			default:
+				drained = true
+			}
+		}
+		l.in <- e
+	}
+}
+
+// next captures the keyboard and returns the event for the next key.
+func (l *Leader) next(ctx context.Context) LeaderEvent {
+	ctx, cancel := context.WithTimeout(ctx, l.timeout)
+	defer cancel()
+	kb, err := l.capture(ctx)
+	if err != nil {
+		return LeaderEvent{Kind: LeaderCanceled, Err: err}
+	}
+	defer kb.close()
+	l.in <- LeaderEvent{Kind: LeaderActive}
+
+	for {
+		k, err := kb.next(ctx)
+		switch {
+		case err != nil:
+			return LeaderEvent{Kind: LeaderCanceled, Err: err}
+		case k.Kind != EventKeydown || isModifierKey(k.Key):
+			continue // the release of the leader, or a modifier of the next key
+		case k.Key == keyEscape:
+			return LeaderEvent{Kind: LeaderCanceled}
+		}
+		return LeaderEvent{Kind: LeaderKey, Press: k}
+	}
+}
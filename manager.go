@@ -0,0 +1,195 @@
+// Copyright 2026 The golang.design Initiative Authors.
+// All rights reserved. Use of this source code is governed
+// by a MIT license that can be found in the LICENSE file.
+
+package hotkey
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// ErrUnknownID is returned by the methods of a Manager for an ID that has
+// not been added to it.
+var ErrUnknownID = errors.New("hotkey: unknown id")
+
+// ManagerEvent is an event of one of the hotkeys of a Manager.
+type ManagerEvent struct {
+	ID    string
+	Event Event
+}
+
+// Manager owns a set of hotkeys, each identified by an ID, and merges
+// their events into a single channel. The zero value is not usable; use
+// NewManager.
+type Manager struct {
+	mu      sync.Mutex
+	entries map[string]*managed
+	closed  bool
+
+	in  chan<- ManagerEvent
+	out <-chan ManagerEvent
+}
+
+type managed struct {
+	hk     *Hotkey
+	remove func() // stops forwarding the events of hk
+}
+
+// NewManager creates an empty manager.
+func NewManager() *Manager {
+	in, out := newEventChan[ManagerEvent]()
+	return &Manager{entries: map[string]*managed{}, in: in, out: out}
+}
+
+// Add registers hk and adds it to the manager under id. The manager takes
+// ownership of hk: its events are delivered on Events, in addition to the
+// Keydown and Keyup channels of hk, and Remove and Close close it. If hk
+// cannot be registered, it is not added and the error is returned.
+func (m *Manager) Add(id string, hk *Hotkey) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return ErrClosed
+	}
+	if _, ok := m.entries[id]; ok {
+		return fmt.Errorf("hotkey: id %q already exists", id)
+	}
+	if err := hk.Register(); err != nil && !errors.Is(err, ErrAlreadyRegistered) {
+		return err
+	}
+	m.entries[id] = &managed{
+		hk: hk,
+		remove: hk.listen(func(e Event) {
+			m.in <- ManagerEvent{ID: id, Event: e}
+		}),
+	}
+	return nil
+}
+
+// Remove closes the hotkey with the given id and removes it from the
+// manager.
+func (m *Manager) Remove(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.entries[id]
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownID, id)
+	}
+	// Closing the hotkey first stops its events, so nothing is
+	// forwarded anymore once the listener is removed.
+	e.hk.Close()
+	e.remove()
+	delete(m.entries, id)
+	return nil
+}
+
+// Lookup returns the hotkey with the given id.
+func (m *Manager) Lookup(id string) (*Hotkey, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.entries[id]
+	if !ok {
+		return nil, false
+	}
+	return e.hk, true
+}
+
+// List returns the IDs of all hotkeys of the manager in sorted order.
+func (m *Manager) List() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	ids := make([]string, 0, len(m.entries))
+	for id := range m.entries {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// Enable registers the hotkey with the given id again after Disable.
+// Enabling an enabled hotkey does nothing.
+func (m *Manager) Enable(id string) error {
+	hk, err := m.lookup(id)
+	if err != nil {
+		return err
+	}
+	if err := hk.Register(); err != nil && !errors.Is(err, ErrAlreadyRegistered) {
+		return err
+	}
+	return nil
+}
+
+// Disable unregisters the hotkey with the given id, keeping it in the
+// manager so it can be enabled later. Disabling a disabled hotkey does
+// nothing.
+func (m *Manager) Disable(id string) error {
+	hk, err := m.lookup(id)
+	if err != nil {
+		return err
+	}
+	if err := hk.Unregister(); err != nil && !errors.Is(err, ErrNotRegistered) {
+		return err
+	}
+	return nil
+}
+
+func (m *Manager) lookup(id string) (*Hotkey, error) {
+	hk, ok := m.Lookup(id)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownID, id)
+	}
+	return hk, nil
+}
+
+// Events returns the channel that receives the events of all hotkeys of
+// the manager, in the order they happened. It is closed by Close.
+func (m *Manager) Events() <-chan ManagerEvent { return m.out }
+
+// Close closes all hotkeys of the manager and the Events channel. Close is
+// idempotent and always returns nil.
+func (m *Manager) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return nil
+	}
+	for id, e := range m.entries {
+		e.hk.Close()
+		e.remove()
+		delete(m.entries, id)
+	}
+	m.closed = true
+	close(m.in)
+	return nil
+}
+
+// managerState is the JSON representation of a Manager.
+type managerState struct {
+	ID      string `json:"id"`
+	Hotkey  string `json:"hotkey"`
+	Enabled bool   `json:"enabled"`
+}
+
+// MarshalJSON returns a snapshot of the hotkeys of the manager, sorted by
+// ID, for debugging.
+func (m *Manager) MarshalJSON() ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	state := make([]managerState, 0, len(m.entries))
+	for id, e := range m.entries {
+		e.hk.mu.Lock()
+		state = append(state, managerState{
+			ID:      id,
+			Hotkey:  e.hk.string(),
+			Enabled: e.hk.registered,
+		})
+		e.hk.mu.Unlock()
+	}
+	slices.SortFunc(state, func(a, b managerState) int { return cmp.Compare(a.ID, b.ID) })
+	return json.Marshal(state)
+}
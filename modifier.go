@@ -0,0 +1,41 @@
+// Copyright 2026 The golang.design Initiative Authors.
+// All rights reserved. Use of this source code is governed
+// by a MIT license that can be found in the LICENSE file.
+
+package hotkey
+
+// modifierOnlyBit marks the physical key of a hotkey made by
+// NewModifierOnly, so that it is a combination of its own.
+const modifierOnlyBit PhysicalKey = 1 << 30
+
+// NewModifierOnly creates a new hotkey that is triggered by pressing and
+// releasing the modifier key alone, such as the left Super key or the
+// right Ctrl key, as returned by PhysicalKeyByName("LWIN") or
+// PhysicalKeyByName("RCTL"). Being a physical key, the left and the right
+// key of a modifier are told apart. The hotkey fires only if no other key
+// and no mouse button was pressed while key was held, so the modifier
+// keeps its meaning in combinations.
+//
+// The key is not grabbed: other applications see its press and release as
+// usual, and a combination such as Super+X still reaches them. Keydown and
+// Keyup are both sent on the release, once it is clear that key was
+// pressed alone.
+//
+// Modifier-only hotkeys are supported on Linux (X11), where they listen to
+// the raw key events of XInput 2.1, which libXi must be installed for.
+// Otherwise, Register returns ErrUnsupported.
+func NewModifierOnly(key PhysicalKey) *Hotkey {
+	return newHotkey(nil, Key(key|modifierOnlyBit), true)
+}
+
+// modifierOnly reports whether hk was made by NewModifierOnly. The caller
+// must hold hk.mu.
+func (hk *Hotkey) modifierOnly() bool {
+	return hk.physical && PhysicalKey(hk.key)&modifierOnlyBit != 0
+}
+
+// physicalKey returns the physical key of hk, without modifierOnlyBit.
+// The caller must hold hk.mu.
+func (hk *Hotkey) physicalKey() PhysicalKey {
+	return PhysicalKey(hk.key) &^ modifierOnlyBit
+}
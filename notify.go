@@ -0,0 +1,66 @@
+// Copyright 2026 The golang.design Initiative Authors.
+// All rights reserved. Use of this source code is governed
+// by a MIT license that can be found in the LICENSE file.
+
+package hotkey
+
+import "sync"
+
+// Triggered is sent by Notify for every event of a combination.
+type Triggered struct {
+	Combination Combination
+	Event       Event
+}
+
+// notifications holds the hotkeys registered by Notify, by channel.
+var notifications = struct {
+	sync.Mutex
+	m map[chan<- Triggered][]*Hotkey
+}{m: map[chan<- Triggered][]*Hotkey{}}
+
+// Notify registers the given combinations and relays their events to c,
+// much like signal.Notify does for signals. Both presses and releases are
+// sent, see Event.Kind. Notify does not block sending to c: the caller
+// must ensure that c has sufficient buffer space to keep up with the
+// expected rate of events, or events are dropped.
+//
+// The combinations are registered all or none, as by RegisterAll. Notify
+// may be called several times with the same channel to add combinations;
+// Stop releases all of them.
+func Notify(c chan<- Triggered, combos ...Combination) error {
+	if c == nil {
+		panic("hotkey: Notify using nil channel")
+	}
+	hks := make([]*Hotkey, len(combos))
+	for i, combo := range combos {
+		hks[i] = newInternal(combo.Mods, combo.Key, false)
+		hks[i].listen(func(e Event) {
+			select {
+			case c <- Triggered{Combination: combo, Event: e}:
+			default:
+			}
+		})
+	}
+	if err := RegisterAll(hks...); err != nil {
+		for _, hk := range hks {
+			hk.Close()
+		}
+		return err
+	}
+
+	notifications.Lock()
+	defer notifications.Unlock()
+	notifications.m[c] = append(notifications.m[c], hks...)
+	return nil
+}
+
+// Stop releases all combinations registered by Notify for c. When Stop
+// returns, no more events are sent to c.
+func Stop(c chan<- Triggered) {
+	notifications.Lock()
+	defer notifications.Unlock()
+	for _, hk := range notifications.m[c] {
+		hk.Close()
+	}
+	delete(notifications.m, c)
+}
@@ -0,0 +1,66 @@
+// Copyright 2026 The golang.design Initiative Authors.
+// All rights reserved. Use of this source code is governed
+// by a MIT license that can be found in the LICENSE file.
+
+package hotkey
+
+import "fmt"
+
+// PhysicalKey identifies a key by its position on the keyboard rather than
+// by the symbol it produces, so a hotkey bound to it keeps working when the
+// user switches keyboard layout (for example, the key left of 1 is the same
+// PhysicalKey on QWERTY, AZERTY and Dvorak).
+//
+// The value is platform specific:
+//
+//   - On Linux (X11), it is an X keycode, which is the evdev code plus 8.
+//   - On Windows, it is a PC/AT (set 1) scancode. Extended keys carry the
+//     0xE0 prefix in the high byte, e.g. 0xE04B for the left arrow.
+//   - On macOS, it is a virtual keycode, the same value as a Key.
+//
+// Use PhysicalKeyByName to obtain a PhysicalKey from its XKB key name.
+type PhysicalKey uint32
+
+// NewPhysical creates a new hotkey for the given modifiers and physical key.
+// Unlike New, the key is not translated through the current keyboard layout.
+func NewPhysical(mods []Modifier, key PhysicalKey) *Hotkey {
+	return newHotkey(mods, Key(key), true)
+}
+
+// AnyKey is the physical key that matches every key. A hotkey on AnyKey
+// grabs all keys pressed together with its modifiers, e.g.
+// NewPhysical([]Modifier{Mod4}, AnyKey) takes every Super+key combination
+// away from other applications, so it must be used with care.
+//
+// AnyKey is supported on Linux (X11) only. Elsewhere, Register returns
+// ErrUnsupported.
+const AnyKey PhysicalKey = 1 << 31
+
+// PhysicalKeyByName returns the physical key with the given XKB key name,
+// such as "TLDE" (the key left of 1), "AE01" (the 1 key), "AC01" (the key
+// right of CapsLock) or "FK05" (F5). See /usr/share/X11/xkb/keycodes/evdev
+// for the full list of names.
+//
+// On Linux (X11), the name is resolved through the keymap of the X server,
+// so aliases and server specific names are accepted. On other platforms,
+// only the names of the main alphanumeric block, the function keys and the
+// keypad are known.
+func PhysicalKeyByName(name string) (PhysicalKey, error) {
+	return physicalKeyByName(name)
+}
+
+// NewRune creates a new hotkey for the given modifiers and the key that
+// types r on the current keyboard layout, such as 'é', '/' or '!'. The
+// modifiers needed to produce r, e.g. Shift for '!' on a US layout, are
+// added to mods. The key is looked up once, so the hotkey does not follow
+// later layout switches.
+//
+// It returns an error wrapping ErrKeyNotOnKeyboard if r cannot be typed on
+// the current layout.
+func NewRune(mods []Modifier, r rune) (*Hotkey, error) {
+	return newRune(mods, r)
+}
+
+func errNotTypable(r rune) error {
+	return fmt.Errorf("%w: %q cannot be typed on the current keyboard layout", ErrKeyNotOnKeyboard, r)
+}
@@ -0,0 +1,118 @@
+// Copyright 2026 The golang.design Initiative Authors.
+// All rights reserved. Use of this source code is governed
+// by a MIT license that can be found in the LICENSE file.
+
+package hotkey
+
+import (
+	"sync"
+	"time"
+)
+
+// repeater emits the repeats of a held hotkey at a fixed pace, see
+// SetRepeat.
+type repeater struct {
+	delay, interval time.Duration
+
+	mu      sync.Mutex
+	stop    chan struct{} // non-nil while the hotkey is held
+	stopped chan struct{}
+	retired bool // replaced by SetRepeat, never starts again
+}
+
+// SetRepeat makes the hotkey report EventRepeat on its own while it is
+// held: first after delay, then every interval, until it is released,
+// unregistered or rebound. The auto-repeat of the system is then ignored,
+// so the pace is the same on every machine, regardless of ReportRepeats.
+// If delay is not positive, interval is used. An interval that is not
+// positive turns the repeats off again, which is the default.
+func (hk *Hotkey) SetRepeat(delay, interval time.Duration) {
+	var r *repeater
+	if interval > 0 {
+		if delay <= 0 {
+			delay = interval
+		}
+		r = &repeater{delay: delay, interval: interval}
+	}
+	if old := hk.repeater.Swap(r); old != nil {
+		old.retire()
+	}
+}
+
+// accept reports whether e is sent on, and starts or stops the repeats of
+// SetRepeat when the hotkey is pressed or released.
+func (hk *Hotkey) accept(e Event) bool {
+	r := hk.repeater.Load()
+	if r == nil {
+		return e.Kind != EventRepeat || hk.repeats.Load()
+	}
+	switch e.Kind {
+	case EventKeydown:
+		r.start(hk)
+	case EventKeyup:
+		r.halt()
+	case EventRepeat:
+		return false
+	}
+	return true
+}
+
+// stopRepeat stops the repeats of SetRepeat when the hotkey can no longer
+// receive its release.
+func (hk *Hotkey) stopRepeat() {
+	if r := hk.repeater.Load(); r != nil {
+		r.halt()
+	}
+}
+
+// start starts repeating, unless the hotkey is held already or r was
+// replaced, maybe after accept loaded it.
+func (r *repeater) start(hk *Hotkey) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.stop != nil || r.retired {
+		return
+	}
+	stop, stopped := make(chan struct{}), make(chan struct{})
+	r.stop, r.stopped = stop, stopped
+	go func() {
+		defer close(stopped)
+		t := time.NewTimer(r.delay)
+		defer t.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-t.C:
+				hk.post(Event{Kind: EventRepeat})
+				t.Reset(r.interval)
+			}
+		}
+	}()
+}
+
+// halt stops repeating and waits until no repeat is being sent, so that
+// none follows the release.
+func (r *repeater) halt() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.haltLocked()
+}
+
+// retire halts r for good.
+func (r *repeater) retire() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.retired = true
+	r.haltLocked()
+}
+
+// haltLocked is halt. The caller must hold r.mu.
+func (r *repeater) haltLocked() {
+	if r.stop == nil {
+		return
+	}
+	close(r.stop)
+	<-r.stopped
+	r.stop, r.stopped = nil, nil
+}
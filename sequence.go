@@ -0,0 +1,258 @@
+// Copyright 2026 The golang.design Initiative Authors.
+// All rights reserved. Use of this source code is governed
+// by a MIT license that can be found in the LICENSE file.
+
+package hotkey
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrSequenceTimeout is reported by a SequenceAborted event when the next
+// stroke of a Sequence was not pressed in time.
+var ErrSequenceTimeout = errors.New("hotkey: sequence timed out")
+
+// SequenceEventKind tells what happened to a Sequence.
+type SequenceEventKind uint8
+
+// The kinds of sequence events.
+const (
+	SequenceProgress SequenceEventKind = iota + 1 // a stroke matched, more are expected
+	SequenceMatched                               // all strokes matched
+	SequenceAborted                               // the sequence was interrupted
+)
+
+// String returns the name of the sequence event kind.
+func (k SequenceEventKind) String() string {
+	switch k {
+	case SequenceProgress:
+		return "progress"
+	case SequenceMatched:
+		return "matched"
+	case SequenceAborted:
+		return "aborted"
+	}
+	return fmt.Sprintf("SequenceEventKind(%d)", uint8(k))
+}
+
+// SequenceEvent is an event of a Sequence.
+type SequenceEvent struct {
+	Kind SequenceEventKind
+	Step int   // the number of strokes matched so far
+	Err  error // why the sequence was aborted
+}
+
+// Sequence is a shortcut made of several strokes pressed one after the
+// other, such as Ctrl+K Ctrl+C. Only the first stroke is registered while
+// the sequence waits; once it is pressed, the next stroke is registered
+// until it is pressed or the timeout expires, so the follow-up strokes
+// keep their usual meaning in other applications the rest of the time.
+// Pressing the first stroke again starts over.
+//
+// Only the expected strokes are grabbed, not the whole keyboard, so a
+// wrong stroke is not seen: it reaches the focused application as usual,
+// and the sequence keeps waiting until the timeout aborts it. Pressing
+// the expected stroke after a wrong one still completes the sequence.
+type Sequence struct {
+	strokes []Combination
+	timeout time.Duration
+	grab    func(c Combination, pressed func()) (release func(), err error)
+
+	mu      sync.Mutex
+	first   func() // releases the first stroke, while registered
+	presses chan<- int
+	stop    chan struct{}
+	stopped chan struct{}
+	closed  bool
+
+	in  chan<- SequenceEvent
+	out <-chan SequenceEvent
+}
+
+// NewSequence creates a sequence of the given strokes. timeout is how long
+// it waits for each stroke after the first one.
+func NewSequence(timeout time.Duration, strokes ...Combination) *Sequence {
+	in, out := newEventChan[SequenceEvent]()
+	return &Sequence{
+		strokes: strokes,
+		timeout: timeout,
+		grab:    grabStroke,
+		in:      in,
+		out:     out,
+	}
+}
+
+// grabStroke registers c and calls pressed whenever it is pressed.
+func grabStroke(c Combination, pressed func()) (func(), error) {
+	hk := newInternal(c.Mods, c.Key, false)
+	hk.listen(func(e Event) {
+		if e.Kind == EventKeydown {
+			pressed()
+		}
+	})
+	if err := hk.Register(); err != nil {
+		hk.Close()
+		return nil, err
+	}
+	return func() { hk.Close() }, nil
+}
+
+// Register registers the first stroke of the sequence.
+func (s *Sequence) Register() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return ErrClosed
+	}
+	if s.first != nil {
+		return ErrAlreadyRegistered
+	}
+	if len(s.strokes) == 0 {
+		return errors.New("hotkey: empty sequence")
+	}
+
+	in, presses := newEventChan[int]()
+	release, err := s.grab(s.strokes[0], func() { in <- 0 })
+	if err != nil {
+		close(in)
+		return err
+	}
+	s.first, s.presses = release, in
+	s.stop, s.stopped = make(chan struct{}), make(chan struct{})
+	go s.run(presses, in)
+	return nil
+}
+
+// Unregister unregisters all strokes of the sequence. A sequence in
+// progress is aborted without an event.
+func (s *Sequence) Unregister() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.first == nil {
+		return ErrNotRegistered
+	}
+	s.release()
+	return nil
+}
+
+// Close unregisters the sequence if it is registered and closes its Events
+// channel. Close is idempotent and always returns nil.
+func (s *Sequence) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	if s.first != nil {
+		s.release()
+	}
+	s.closed = true
+	close(s.in)
+	return nil
+}
+
+// release stops the sequence. The caller must hold s.mu.
+func (s *Sequence) release() {
+	s.first()
+	close(s.stop)
+	<-s.stopped
+	close(s.presses)
+	s.first = nil
+}
+
+// Events returns the channel that receives the progress of the sequence.
+// It is closed by Close.
+func (s *Sequence) Events() <-chan SequenceEvent { return s.out }
+
+// String returns a string representation of the sequence, its strokes
+// separated by spaces.
+func (s *Sequence) String() string {
+	strokes := make([]string, len(s.strokes))
+	for i, c := range s.strokes {
+		strokes[i] = c.String()
+	}
+	return strings.Join(strokes, " ")
+}
+
+// run matches the presses of the strokes, identified by the index of the
+// first stroke with their combination, until stop is closed. Follow-up
+// strokes are grabbed as they become expected and released when the
+// sequence matches or is aborted.
+func (s *Sequence) run(presses <-chan int, in chan<- int) {
+	defer close(s.stopped)
+
+	var (
+		step     int
+		timeout  <-chan time.Time
+		releases []func()
+	)
+	reset := func() {
+		for _, release := range releases {
+			release()
+		}
+		step, timeout, releases = 0, nil, nil
+	}
+	defer reset()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-timeout:
+			matched := step
+			reset()
+			s.in <- SequenceEvent{Kind: SequenceAborted, Step: matched, Err: ErrSequenceTimeout}
+			continue
+		case i := <-presses:
+			switch {
+			case step > 0 && s.same(i, step):
+				step++
+			case s.same(i, 0):
+				reset()
+				step = 1
+			default:
+				continue // a stroke pressed too late, or out of order
+			}
+		}
+
+		if step == len(s.strokes) {
+			reset()
+			s.in <- SequenceEvent{Kind: SequenceMatched, Step: len(s.strokes)}
+			continue
+		}
+		if !s.grabbed(step) {
+			next := step
+			release, err := s.grab(s.strokes[next], func() { in <- next })
+			if err != nil {
+				matched := step
+				reset()
+				s.in <- SequenceEvent{Kind: SequenceAborted, Step: matched, Err: err}
+				continue
+			}
+			releases = append(releases, release)
+		}
+		timeout = time.After(s.timeout)
+		s.in <- SequenceEvent{Kind: SequenceProgress, Step: step}
+	}
+}
+
+// grabbed reports whether an earlier stroke has the combination of stroke
+// i, so that it is grabbed already while i is expected.
+func (s *Sequence) grabbed(i int) bool {
+	for j := range i {
+		if s.same(j, i) {
+			return true
+		}
+	}
+	return false
+}
+
+// same reports whether strokes i and j are the same combination.
+func (s *Sequence) same(i, j int) bool {
+	a, b := s.strokes[i], s.strokes[j]
+	return comboOf(a.Mods, a.Key, false) == comboOf(b.Mods, b.Key, false)
+}
@@ -0,0 +1,219 @@
+// Copyright 2026 The golang.design Initiative Authors.
+// All rights reserved. Use of this source code is governed
+// by a MIT license that can be found in the LICENSE file.
+
+package hotkey
+
+import (
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// Propagation decides how the hotkeys of this process that share a
+// combination receive its events.
+type Propagation uint8
+
+// The propagation modes.
+const (
+	// Broadcast delivers every event to all hotkeys at once.
+	Broadcast Propagation = iota
+	// Prioritized delivers every event to one hotkey after another,
+	// highest priority first, waiting for the handlers of each, until a
+	// handler calls Event.StopPropagation.
+	Prioritized
+)
+
+// combo identifies a combination regardless of the order of its modifiers.
+type combo struct {
+	mods     Modifier
+	key      Key
+	physical bool
+}
+
+// comboOf returns the combo of mods+key as given. See resolveCombo for the
+// combo that the platform registers.
+func comboOf(mods []Modifier, key Key, physical bool) combo {
+	c := combo{key: key, physical: physical}
+	for _, m := range mods {
+		c.mods |= m
+	}
+	return c
+}
+
+// binding is the registration of a combination with the platform, shared
+// by all registered hotkeys of this process with that combination.
+type binding struct {
+	combo       combo
+	owner       *Hotkey   // holds the platform registration, never exposed
+	joined      []*Hotkey // in registration order
+	members     atomic.Pointer[[]*Hotkey]
+	propagation atomic.Uint32
+	queue       chan<- Event // feeds the Prioritized delivery
+}
+
+// bindings holds the bindings of this process by combination, and the
+// propagation modes set for combinations.
+var bindings = struct {
+	sync.Mutex
+	m           map[combo]*binding
+	propagation map[combo]Propagation
+}{m: map[combo]*binding{}, propagation: map[combo]Propagation{}}
+
+// acquire joins hk to the binding of mods+key, registering the combination
+// with the platform if no other hotkey of this process holds it. The
+// caller must hold hk.mu.
+func acquire(hk *Hotkey, mods []Modifier, key Key, physical bool) (*binding, error) {
+	c, err := resolveCombo(mods, key, physical)
+	if err != nil {
+		return nil, err
+	}
+	bindings.Lock()
+	defer bindings.Unlock()
+	b := bindings.m[c]
+	if b == nil {
+		owner := newInternal(mods, key, physical)
+		owner.repeats.Store(true) // the members decide
+		owner.mu.Lock()
+		err := owner.register()
+		owner.mu.Unlock()
+		if err != nil {
+			owner.Close()
+			return nil, err
+		}
+
+		b = newBinding(c, owner)
+		bindings.m[c] = b
+	}
+	b.joined = append(b.joined, hk)
+	b.sort()
+	return b, nil
+}
+
+// newBinding creates the binding of c, whose platform registration owner
+// already holds, and relays the events of owner to its members. The caller
+// must hold bindings.
+func newBinding(c combo, owner *Hotkey) *binding {
+	in, out := newEventChan[Event]()
+	b := &binding{combo: c, owner: owner, queue: in}
+	b.propagation.Store(uint32(bindings.propagation[c]))
+	b.sort() // the event loop of owner runs already, relay needs members
+	owner.listen(b.relay)
+	go func() {
+		for e := range out {
+			b.prioritize(e)
+		}
+	}()
+	return b
+}
+
+// release removes hk from b and unregisters the combination with the
+// platform once no hotkey of this process is left. The caller must hold
+// hk.mu.
+func (b *binding) release(hk *Hotkey) {
+	bindings.Lock()
+	defer bindings.Unlock()
+	b.joined = slices.DeleteFunc(b.joined, func(x *Hotkey) bool { return x == hk })
+	b.sort()
+	if len(b.joined) > 0 {
+		return
+	}
+
+	b.owner.mu.Lock()
+	b.owner.unregister()
+	b.owner.mu.Unlock()
+	b.owner.Close()
+	close(b.queue)
+	delete(bindings.m, b.combo)
+}
+
+// sort updates the members of b, ordered by priority and then by
+// registration. The caller must hold bindings.
+func (b *binding) sort() {
+	ms := slices.Clone(b.joined)
+	slices.SortStableFunc(ms, func(x, y *Hotkey) int { return y.priority - x.priority })
+	b.members.Store(&ms)
+}
+
+// relay passes an event of the platform registration on to the members. It
+// runs on the platform event loop.
+func (b *binding) relay(e Event) {
+	if Propagation(b.propagation.Load()) == Prioritized {
+		b.queue <- e
+		return
+	}
+	for _, hk := range *b.members.Load() {
+		hk.send(e)
+	}
+}
+
+// prioritize passes an event on to one member after another, until one of
+// them stops its propagation.
+func (b *binding) prioritize(e Event) {
+	e.stop = new(atomic.Bool)
+	for _, hk := range *b.members.Load() {
+		hk.sendAndWait(e)
+		if e.stop.Load() {
+			return
+		}
+	}
+}
+
+// StopPropagation keeps the event from the hotkeys of this process with a
+// lower priority that share its combination. It only has an effect in a
+// handler, when the combination is Prioritized.
+func (e Event) StopPropagation() {
+	if e.stop != nil {
+		e.stop.Store(true)
+	}
+}
+
+// SetPropagation sets how the events of the combination of hk are
+// propagated among all hotkeys of this process that share it. The default
+// is Broadcast. The mode belongs to the combination, so it is kept across
+// Rebind, and applies to hotkeys that register it later.
+func (hk *Hotkey) SetPropagation(p Propagation) {
+	hk.mu.Lock()
+	c := sharedCombo(hk.mods, hk.key, hk.physical)
+	hk.mu.Unlock()
+
+	bindings.Lock()
+	defer bindings.Unlock()
+	bindings.propagation[c] = p
+	if b := bindings.m[c]; b != nil {
+		b.propagation.Store(uint32(p))
+	}
+}
+
+// SetPriority sets the priority of hk among the hotkeys of this process
+// that share its combination when it is Prioritized. Hotkeys with a higher
+// priority receive events first; hotkeys with the same priority receive
+// them in the order they were registered. The default priority is 0.
+func (hk *Hotkey) SetPriority(priority int) {
+	bindings.Lock()
+	defer bindings.Unlock()
+	hk.priority = priority
+	for _, b := range bindings.m {
+		if slices.Contains(b.joined, hk) {
+			b.sort()
+		}
+	}
+}
+
+// isShared reports whether a hotkey of this process holds mods+key.
+func isShared(mods []Modifier, key Key) bool {
+	c := sharedCombo(mods, key, false)
+	bindings.Lock()
+	defer bindings.Unlock()
+	return bindings.m[c] != nil
+}
+
+// sharedCombo is resolveCombo, falling back to the combo as given for a
+// key that cannot be resolved, which no binding holds.
+func sharedCombo(mods []Modifier, key Key, physical bool) combo {
+	c, err := resolveCombo(mods, key, physical)
+	if err != nil {
+		return comboOf(mods, key, physical)
+	}
+	return c
+}
@@ -0,0 +1,121 @@
+// Copyright 2026 The golang.design Initiative Authors.
+// All rights reserved. Use of this source code is governed
+// by a MIT license that can be found in the LICENSE file.
+
+package hotkey
+
+import "sync"
+
+// Overflow tells what a subscription does with an event that does not fit
+// into its buffer.
+type Overflow uint8
+
+// The overflow policies. Events are never waited for, so a slow
+// subscriber cannot hold up the others.
+const (
+	DropNewest Overflow = iota // the new event is dropped
+	DropOldest                 // the oldest buffered event is dropped to make room
+)
+
+// Buffer is the buffer policy of a subscription.
+type Buffer struct {
+	Size     int      // capacity of the channel; 0 means unlimited
+	Overflow Overflow // what to drop once Size events are buffered
+}
+
+// subscription is a channel returned by Subscribe.
+type subscription struct {
+	mu     sync.Mutex
+	closed bool
+	in     chan<- Event
+	send   func(Event) // sends to in, following the buffer policy
+	remove func()      // removes the listener of the subscription
+}
+
+// Subscribe returns a new channel that receives every event of the hotkey,
+// both presses and releases, independently of Keydown, Keyup and other
+// subscribers, together with a function that ends the subscription and
+// closes the channel. The channel is buffered as described by b. Close
+// ends all subscriptions of the hotkey.
+func (hk *Hotkey) Subscribe(b Buffer) (<-chan Event, func()) {
+	s := &subscription{}
+	var out <-chan Event
+	switch {
+	case b.Size <= 0:
+		in, o := newEventChan[Event]()
+		s.in, out = in, o
+		s.send = func(e Event) { in <- e }
+	case b.Overflow == DropOldest:
+		c := make(chan Event, b.Size)
+		s.in, out = c, c
+		s.send = func(e Event) {
+			for {
+				select {
+				case c <- e:
+					return
+				default:
+				}
+				select {
+				case <-c:
+				default:
+				}
+			}
+		}
+	default:
+		c := make(chan Event, b.Size)
+		s.in, out = c, c
+		s.send = func(e Event) {
+			select {
+			case c <- e:
+			default:
+			}
+		}
+	}
+
+	hk.lmu.Lock()
+	defer hk.lmu.Unlock()
+	if hk.subs == nil {
+		close(s.in)
+		return out, func() {}
+	}
+	s.remove = hk.listenLocked(s.deliver)
+	hk.subs[s] = struct{}{}
+	return out, func() {
+		hk.lmu.Lock()
+		delete(hk.subs, s)
+		hk.lmu.Unlock()
+		s.close()
+	}
+}
+
+func (s *subscription) deliver(e Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.closed {
+		s.send(e)
+	}
+}
+
+// close ends the subscription. An event that is being emitted concurrently
+// is dropped rather than sent on the closed channel.
+func (s *subscription) close() {
+	s.remove()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.closed {
+		s.closed = true
+		close(s.in)
+	}
+}
+
+// closeSubscriptions ends all subscriptions of the hotkey. It is called by
+// Close.
+func (hk *Hotkey) closeSubscriptions() {
+	hk.lmu.Lock()
+	subs := hk.subs
+	hk.subs = nil
+	hk.lmu.Unlock()
+	for s := range subs {
+		s.close()
+	}
+}